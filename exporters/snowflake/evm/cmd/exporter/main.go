@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"errors"
 	"flag"
 	"fmt"
 	"log"
@@ -180,7 +179,7 @@ func runOneBatch(ctx context.Context, sfClient *snowflake.Client, ingClient *cli
 	targetBlock := min(fromBlock+int64(cfg.BatchSize)-1, int64(info.LatestBlock))
 	log.Printf("Fetching blocks %d-%d (latest available: %d)", fromBlock, targetBlock, info.LatestBlock)
 
-	// 4. Stream blocks
+	// 4. Download blocks
 	blocks, err := fetchBlocks(ctx, ingClient, uint64(fromBlock), uint64(targetBlock))
 	if err != nil {
 		return 0, fmt.Errorf("fetch blocks %d-%d: %w", fromBlock, targetBlock, err)
@@ -204,27 +203,17 @@ func runOneBatch(ctx context.Context, sfClient *snowflake.Client, ingClient *cli
 	return blocksWritten, nil
 }
 
+// fetchBlocks downloads a closed range over HTTP; targetBlock is always <= the sink's latest block
 func fetchBlocks(ctx context.Context, ingClient *client.Client, fromBlock, toBlock uint64) ([]rpc.NormalizedBlock, error) {
-	streamCtx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	var blocks []rpc.NormalizedBlock
-
-	err := ingClient.Stream(streamCtx, fromBlock, func(blockBatch []client.Block) error {
-		for _, b := range blockBatch {
-			blocks = append(blocks, *b.Data)
-			if b.Number >= toBlock {
-				cancel()
-				break
-			}
-		}
-		return nil
-	})
-
-	if err != nil && !errors.Is(err, context.Canceled) {
+	received, err := ingClient.GetBlocks(ctx, fromBlock, toBlock)
+	if err != nil {
 		return nil, err
 	}
 
+	blocks := make([]rpc.NormalizedBlock, len(received))
+	for i, b := range received {
+		blocks[i] = *b.Data
+	}
 	return blocks, nil
 }
 
@@ -30,7 +30,11 @@ func Transform(blocks []rpc.NormalizedBlock) *ExportBatch {
 			}
 		}
 
-		// Transform traces (internal transactions)
+		// Transform traces (internal transactions). Receipts-only and
+		// prestateDiff chains carry no call trees, so they have none.
+		if !hasCallTraces(&nb) {
+			continue
+		}
 		for _, traceResult := range nb.Traces {
 			if traceResult.Result != nil {
 				rows := FlattenCallTrace(b, traceResult.TxHash, traceResult.Result, "", 0)
@@ -41,3 +45,12 @@ func Transform(blocks []rpc.NormalizedBlock) *ExportBatch {
 
 	return batch
 }
+
+// hasCallTraces reports whether the block was stored with call trees
+func hasCallTraces(nb *rpc.NormalizedBlock) bool {
+	switch nb.TraceMode {
+	case rpc.TraceModeNone, rpc.TraceModePrestateDiff:
+		return false
+	}
+	return len(nb.Traces) > 0
+}
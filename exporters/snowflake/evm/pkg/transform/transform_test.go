@@ -388,3 +388,25 @@ func TestTransformMessages(t *testing.T) {
 
 	compareRowSets(t, "messages", got, want)
 }
+
+func TestTransformReceiptsOnly(t *testing.T) {
+	raw := `{
+		"block": {"number":"0x10","hash":"0xb1","timestamp":"0x64","gasLimit":"0x1","gasUsed":"0x1",
+			"transactions":[{"hash":"0xaa","from":"0x01","to":"0x02","gas":"0x5208","gasPrice":"0x1","value":"0x0","input":"0x","nonce":"0x0","transactionIndex":"0x0","type":"0x0"}]},
+		"receipts": [{"transactionHash":"0xaa","gasUsed":"0x5208","cumulativeGasUsed":"0x5208","status":"0x1",
+			"logs":[{"address":"0x02","topics":["0xdd"],"data":"0x","logIndex":"0x0"}]}],
+		"traceMode": "none"
+	}`
+	var nb rpc.NormalizedBlock
+	if err := json.Unmarshal([]byte(raw), &nb); err != nil {
+		t.Fatal(err)
+	}
+
+	batch := transform.Transform([]rpc.NormalizedBlock{nb})
+	if len(batch.Transactions) != 1 || len(batch.Receipts) != 1 || len(batch.Logs) != 1 {
+		t.Fatalf("got %d txs, %d receipts, %d logs", len(batch.Transactions), len(batch.Receipts), len(batch.Logs))
+	}
+	if len(batch.InternalTxs) != 0 {
+		t.Fatalf("got %d internal txs for a receipts-only block", len(batch.InternalTxs))
+	}
+}
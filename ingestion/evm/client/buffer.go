@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/containerman17/l1-data-tools/ingestion/evm/rpc/metrics"
+	"github.com/containerman17/l1-data-tools/ingestion/evm/rpc/rpc"
 )
 
 type BufferConfig struct {
@@ -31,6 +32,7 @@ type receiveBuffer struct {
 type bufferedItem struct {
 	compressedData []byte
 	size           int64
+	control        *rpc.ControlFrame // Set for text frames, compressedData is nil
 }
 
 func newReceiveBuffer(cfg BufferConfig) *receiveBuffer {
@@ -74,6 +76,19 @@ func (b *receiveBuffer) push(data []byte) {
 	b.cond.Signal()
 }
 
+// pushControl queues a control frame in order with the block frames
+func (b *receiveBuffer) pushControl(frame *rpc.ControlFrame) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.items = append(b.items, bufferedItem{control: frame})
+	b.cond.Signal()
+}
+
 func (b *receiveBuffer) sliceBatch(maxSize int64) []bufferedItem {
 	b.mu.Lock()
 	defer b.mu.Unlock()
@@ -14,6 +14,7 @@ type ChainInfo struct {
 	EvmChainId int    `json:"evmChainId"`
 	SubnetId   string `json:"subnetId"`
 	Indexer    string `json:"indexer"`
+	Info       string `json:"info,omitempty"`
 }
 
 // NewFromCatalogue fetches /chains and returns a client for each chain.
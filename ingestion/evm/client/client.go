@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"sync"
@@ -32,6 +34,39 @@ type Client struct {
 	zstdDec      *zstd.Decoder
 	reconnect    bool
 	bufferConfig BufferConfig
+
+	rollbackHandler RollbackHandler
+	filter          url.Values
+}
+
+// Filter selects transactions server-side. A transaction is kept if it matches
+// any criterion; blocks without matches arrive with no transactions.
+type Filter struct {
+	Addresses     []string // Log emitters, tx recipients and call frame from/to
+	Topics        []string // Any log topic position
+	TxFrom        []string
+	TxTo          []string
+	ExcludeTraces bool
+}
+
+func (f Filter) query() url.Values {
+	q := url.Values{}
+	if len(f.Addresses) > 0 {
+		q.Set("addresses", strings.Join(f.Addresses, ","))
+	}
+	if len(f.Topics) > 0 {
+		q.Set("topics", strings.Join(f.Topics, ","))
+	}
+	if len(f.TxFrom) > 0 {
+		q.Set("txFrom", strings.Join(f.TxFrom, ","))
+	}
+	if len(f.TxTo) > 0 {
+		q.Set("txTo", strings.Join(f.TxTo, ","))
+	}
+	if f.ExcludeTraces {
+		q.Set("traces", "false")
+	}
+	return q
 }
 
 // NewClient creates a new sink client
@@ -56,6 +91,20 @@ func NewClient(addr string, opts ...Option) *Client {
 // Real-time: 1 block per pack.
 type Handler func(blocks []Block) error
 
+// RollbackHandler is called when the server replaced blocks above toBlock.
+// Blocks already handled above toBlock must be undone; streaming resumes at toBlock+1.
+type RollbackHandler func(toBlock uint64) error
+
+// RollbackError is returned by Stream when the server rolls back and no
+// RollbackHandler is configured
+type RollbackError struct {
+	ToBlock uint64
+}
+
+func (e *RollbackError) Error() string {
+	return fmt.Sprintf("server rolled back to block %d", e.ToBlock)
+}
+
 // Stream connects and streams block packs, calling handler for each pack.
 // Automatically reconnects on disconnect if enabled.
 func (c *Client) Stream(ctx context.Context, fromBlock uint64, handler Handler) error {
@@ -104,15 +153,19 @@ func (c *Client) Stream(ctx context.Context, fromBlock uint64, handler Handler)
 				return ctx.Err()
 			}
 
-			if !c.reconnect {
+			// Unhandled rollback - the caller has to undo work before resuming
+			var rollbackErr *RollbackError
+			if errors.As(err, &rollbackErr) {
 				return err
 			}
 
-			// Update resume position if we processed anything
-			if lastBlock >= currentBlock {
-				currentBlock = lastBlock + 1
+			if !c.reconnect {
+				return err
 			}
 
+			// Resume after the last processed block (may move back after a rollback)
+			currentBlock = lastBlock + 1
+
 			time.Sleep(5 * time.Second)
 			continue
 		}
@@ -132,7 +185,7 @@ func (c *Client) receiveLoop(ctx context.Context, buf *receiveBuffer, errCh chan
 		default:
 		}
 
-		_, data, err := c.conn.ReadMessage()
+		msgType, data, err := c.conn.ReadMessage()
 		if err != nil {
 			select {
 			case errCh <- err:
@@ -141,6 +194,19 @@ func (c *Client) receiveLoop(ctx context.Context, buf *receiveBuffer, errCh chan
 			return
 		}
 
+		if msgType == websocket.TextMessage {
+			var frame rpc.ControlFrame
+			if err := json.Unmarshal(data, &frame); err != nil {
+				select {
+				case errCh <- fmt.Errorf("parse control frame: %w", err):
+				default:
+				}
+				return
+			}
+			buf.pushControl(&frame)
+			continue
+		}
+
 		// Copy data (websocket buffer is reused)
 		dataCopy := make([]byte, len(data))
 		copy(dataCopy, data)
@@ -177,6 +243,28 @@ func (c *Client) processLoop(ctx context.Context, buf *receiveBuffer, fromBlock
 		// Process batch
 		var allBlocks []Block
 		for _, item := range batch {
+			if item.control != nil {
+				if item.control.Type != rpc.ControlFrameRollback {
+					continue
+				}
+				// Deliver what precedes the rollback, then rewind
+				if len(allBlocks) > 0 {
+					if err := handler(allBlocks); err != nil {
+						return currentBlock - 1, err
+					}
+					allBlocks = nil
+				}
+				toBlock := item.control.ToBlock
+				if c.rollbackHandler == nil {
+					return toBlock, &RollbackError{ToBlock: toBlock}
+				}
+				if err := c.rollbackHandler(toBlock); err != nil {
+					return toBlock, err
+				}
+				currentBlock = toBlock + 1
+				continue
+			}
+
 			decompressed, err := c.zstdDec.DecodeAll(item.compressedData, nil)
 			if err != nil {
 				return currentBlock - 1, fmt.Errorf("decompress: %w", err)
@@ -225,6 +313,7 @@ func (c *Client) Close() error {
 type InfoResponse struct {
 	ChainID     string `json:"chainID"`
 	LatestBlock uint64 `json:"latestBlock"`
+	TraceMode   string `json:"traceMode"` // callTracer, callTracerWithLogs, prestateDiff or none
 }
 
 // Info fetches chain information from the /info endpoint
@@ -259,8 +348,11 @@ func (c *Client) Info(ctx context.Context) (*InfoResponse, error) {
 }
 
 func (c *Client) connect(ctx context.Context, fromBlock uint64) error {
-	url := fmt.Sprintf("ws://%s/ws?from=%d", c.addr, fromBlock)
-	conn, _, err := (&websocket.Dialer{HandshakeTimeout: 10 * time.Second}).DialContext(ctx, url, nil)
+	wsURL := fmt.Sprintf("ws://%s/ws?from=%d", c.addr, fromBlock)
+	if len(c.filter) > 0 {
+		wsURL += "&" + c.filter.Encode()
+	}
+	conn, _, err := (&websocket.Dialer{HandshakeTimeout: 10 * time.Second}).DialContext(ctx, wsURL, nil)
 	if err != nil {
 		return fmt.Errorf("connect: %w", err)
 	}
@@ -16,3 +16,18 @@ func WithBufferConfig(cfg BufferConfig) Option {
 		c.bufferConfig = cfg
 	}
 }
+
+// WithRollbackHandler sets the callback invoked when the server rolls back
+// blocks after a reorg. Without it, Stream returns a *RollbackError.
+func WithRollbackHandler(h RollbackHandler) Option {
+	return func(c *Client) {
+		c.rollbackHandler = h
+	}
+}
+
+// WithFilter asks the server to stream only matching transactions (see Filter)
+func WithFilter(f Filter) Option {
+	return func(c *Client) {
+		c.filter = f.query()
+	}
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/containerman17/l1-data-tools/ingestion/evm/rpc/consts"
+	"github.com/containerman17/l1-data-tools/ingestion/evm/rpc/rpc"
+)
+
+// GetBlocks downloads blocks [from, to] over HTTP (GET /blocks) instead of streaming.
+// Ranges larger than the server limit are fetched in consecutive requests.
+// Independent ranges can be fetched in parallel from separate goroutines.
+func (c *Client) GetBlocks(ctx context.Context, from, to uint64) ([]Block, error) {
+	if from == 0 {
+		from = 1 // Genesis is not stored, same as /ws
+	}
+	if to < from {
+		return nil, fmt.Errorf("invalid range %d-%d", from, to)
+	}
+
+	blocks := make([]Block, 0, to-from+1)
+	for start := from; start <= to; start += consts.ServerMaxRangeBlocks {
+		end := min(start+consts.ServerMaxRangeBlocks-1, to)
+		chunk, err := c.getBlockRange(ctx, start, end)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, chunk...)
+	}
+	return blocks, nil
+}
+
+func (c *Client) getBlockRange(ctx context.Context, from, to uint64) ([]Block, error) {
+	httpURL := fmt.Sprintf("http://%s/blocks?from=%d&to=%d", c.addr, from, to)
+	req, err := http.NewRequestWithContext(ctx, "GET", httpURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("get blocks %d-%d: %w", from, to, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("blocks %d-%d returned %d: %s", from, to, resp.StatusCode, string(body))
+	}
+
+	compressed, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read blocks %d-%d: %w", from, to, err)
+	}
+	decompressed, err := c.zstdDec.DecodeAll(compressed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decompress: %w", err)
+	}
+
+	blocks := make([]Block, 0, to-from+1)
+	for _, line := range bytes.Split(decompressed, []byte{'\n'}) {
+		if len(line) == 0 {
+			continue
+		}
+		var nb rpc.NormalizedBlock
+		if err := json.Unmarshal(line, &nb); err != nil {
+			return nil, fmt.Errorf("parse block: %w", err)
+		}
+		blockNum, err := parseHex(nb.Block.Number)
+		if err != nil {
+			return nil, fmt.Errorf("parse block number: %w", err)
+		}
+		if expected := from + uint64(len(blocks)); blockNum != expected {
+			return nil, fmt.Errorf("got block %d, expected %d", blockNum, expected)
+		}
+		blocks = append(blocks, Block{Number: blockNum, Data: &nb})
+	}
+	if uint64(len(blocks)) != to-from+1 {
+		return nil, fmt.Errorf("got %d blocks for range %d-%d", len(blocks), from, to)
+	}
+	return blocks, nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/subnet-evm/eth/tracers"
 	"github.com/ava-labs/subnet-evm/rpc"
+	sinkrpc "github.com/containerman17/l1-data-tools/ingestion/evm/rpc/rpc"
 )
 
 // Stats for periodic logging (protected by mutex)
@@ -48,23 +49,20 @@ func (vm *IndexingVM) indexBlock(ctx context.Context, height uint64) error {
 		receiptsRPC[i] = marshalReceipt(receipt, block.Hash(), block.NumberU64(), uint64(i), block.Transactions()[i], vm.config)
 	}
 
-	// Get traces
-	if vm.tracerAPI == nil {
-		return fmt.Errorf("tracerAPI not available")
-	}
-	tracerName := "callTracer"
-	cfg := &tracers.TraceConfig{Tracer: &tracerName}
-	blockNum := rpc.BlockNumber(height)
-	tracesRPC, err := vm.tracerAPI.TraceBlockByNumber(ctx, blockNum, cfg)
-	if err != nil {
-		return fmt.Errorf("trace block %d: %w", height, err)
-	}
-
 	// Build normalized block
 	normalized := map[string]interface{}{
-		"block":    blockRPC,
-		"receipts": receiptsRPC,
-		"traces":   tracesRPC,
+		"block":     blockRPC,
+		"receipts":  receiptsRPC,
+		"traceMode": vm.traceMode,
+	}
+
+	// Get traces
+	if vm.traceMode != sinkrpc.TraceModeNone {
+		traces, err := vm.traceBlock(ctx, height)
+		if err != nil {
+			return err
+		}
+		normalized["traces"] = traces
 	}
 
 	data, err := json.Marshal(normalized)
@@ -82,9 +80,9 @@ func (vm *IndexingVM) indexBlock(ctx context.Context, height uint64) error {
 	// Otherwise, if Accept() fails after indexBlock(), the skip check will
 	// trigger on retry and we'll create a gap.
 
-	// Update server (for live streaming - acceptable to be slightly ahead)
+	// Publish to live streams (acceptable to be slightly ahead)
 	if vm.server != nil {
-		vm.server.UpdateLatestBlock(height)
+		vm.server.PublishBlock(height, data)
 	}
 
 	// Update stats and log periodically
@@ -94,6 +92,40 @@ func (vm *IndexingVM) indexBlock(ctx context.Context, height uint64) error {
 	return nil
 }
 
+// traceBlock runs the configured tracer over every transaction of the block.
+// prestateDiff results are moved from "result" to "stateDiff" to match the sink's block format.
+func (vm *IndexingVM) traceBlock(ctx context.Context, height uint64) (interface{}, error) {
+	if vm.tracerAPI == nil {
+		return nil, fmt.Errorf("tracerAPI not available")
+	}
+	tracerName, tracerConfig := vm.traceMode.Tracer()
+	cfg := &tracers.TraceConfig{Tracer: &tracerName, TracerConfig: tracerConfig}
+	tracesRPC, err := vm.tracerAPI.TraceBlockByNumber(ctx, rpc.BlockNumber(height), cfg)
+	if err != nil {
+		return nil, fmt.Errorf("trace block %d: %w", height, err)
+	}
+	if vm.traceMode != sinkrpc.TraceModePrestateDiff {
+		return tracesRPC, nil
+	}
+
+	raw, err := json.Marshal(tracesRPC)
+	if err != nil {
+		return nil, fmt.Errorf("marshal traces of block %d: %w", height, err)
+	}
+	var results []struct {
+		TxHash string          `json:"txHash"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(raw, &results); err != nil {
+		return nil, fmt.Errorf("unmarshal traces of block %d: %w", height, err)
+	}
+	traces := make([]map[string]interface{}, len(results))
+	for i, r := range results {
+		traces[i] = map[string]interface{}{"txHash": r.TxHash, "result": nil, "stateDiff": r.Result}
+	}
+	return traces, nil
+}
+
 // updateStats tracks indexing performance and logs periodically
 func (vm *IndexingVM) updateStats(height uint64, size int, elapsed time.Duration) {
 	statsMu.Lock()
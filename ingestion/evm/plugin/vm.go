@@ -19,6 +19,7 @@ import (
 	"github.com/ava-labs/avalanchego/utils/logging"
 
 	"github.com/containerman17/l1-data-tools/ingestion/evm/rpc/api"
+	sinkrpc "github.com/containerman17/l1-data-tools/ingestion/evm/rpc/rpc"
 	"github.com/containerman17/l1-data-tools/ingestion/evm/rpc/storage"
 
 	"github.com/ava-labs/subnet-evm/core"
@@ -54,6 +55,9 @@ type IndexingVM struct {
 	lastAcceptedHeight atomic.Uint64
 	lastIndexedHeight  atomic.Uint64
 
+	// What indexBlock stores in "traces" (GRPC_INDEXER_TRACE_MODE)
+	traceMode sinkrpc.TraceMode
+
 	// Compactor (shared implementation)
 	compactor *storage.Compactor
 
@@ -96,6 +100,15 @@ func (vm *IndexingVM) Initialize(
 		return errChainNotAllowed
 	}
 
+	traceMode, err := sinkrpc.ParseTraceMode(os.Getenv("GRPC_INDEXER_TRACE_MODE"))
+	if err != nil {
+		return fmt.Errorf("GRPC_INDEXER_TRACE_MODE: %w", err)
+	}
+	if traceMode == sinkrpc.TraceModeAuto {
+		traceMode = sinkrpc.TraceModeCall // The in-process tracer API is always available
+	}
+	vm.traceMode = traceMode
+
 	// Initialize the underlying VM first (creates versiondb internally)
 	if err := vm.VM.Initialize(ctx, chainCtx, db, genesisBytes, upgradeBytes, configBytes, fxs, appSender); err != nil {
 		return err
@@ -112,6 +125,22 @@ func (vm *IndexingVM) Initialize(
 	vm.store = storage.NewVersionDBStorage(indexerDB)
 	vm.logger.Info("IndexingVM: using versiondb for atomic commits")
 
+	// Seed a fresh indexer from an exported archive instead of re-tracing history
+	if importDir := os.Getenv("GRPC_INDEXER_IMPORT_DIR"); importDir != "" {
+		if _, hasBatches := vm.store.LatestBatch(); !hasBatches && vm.store.GetMeta() == 0 {
+			last, err := storage.ImportArchive(vm.store, importDir, chainCtx.ChainID.String(), func(end uint64) error {
+				vm.logger.Info("IndexingVM: imported archive segment",
+					logging.UserString("through", fmt.Sprintf("%d", end)))
+				return vdb.Commit() // Keep versiondb.mem bounded to one segment
+			})
+			if err != nil {
+				return fmt.Errorf("import archive from %s: %w", importDir, err)
+			}
+			vm.logger.Info("IndexingVM: archive imported",
+				logging.UserString("lastBlock", fmt.Sprintf("%d", last)))
+		}
+	}
+
 	// Extract internal fields via reflection
 	vm.eth = vm.getEthFromVM()
 	if vm.eth == nil {
@@ -174,6 +203,7 @@ func (vm *IndexingVM) Initialize(
 
 	// Start firehose server
 	vm.server = api.NewServer(vm.store, chainCtx.ChainID.String())
+	vm.server.SetTraceMode(string(vm.traceMode))
 	// Initialize server's latestBlock from restored lastIndexed (otherwise stays 0 until new blocks arrive)
 	if lastIndexed > 0 {
 		vm.server.UpdateLatestBlock(lastIndexed)
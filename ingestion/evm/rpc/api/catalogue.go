@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CatalogueEntry describes one chain in the GET /chains response
+type CatalogueEntry struct {
+	Name       string `json:"name"`
+	EvmChainID uint64 `json:"evmChainId,omitempty"`
+	SubnetID   string `json:"subnetId,omitempty"`
+	Indexer    string `json:"indexer"`
+	Info       string `json:"info"`
+}
+
+// Catalogue serves several chains from one listener.
+// Each chain is mounted at /indexer/{blockchainId}/ and listed in GET /chains.
+type Catalogue struct {
+	mu      sync.RWMutex
+	entries map[string]CatalogueEntry
+	servers map[string]*Server
+	root    *Server // Also served at / for single-chain deployments
+
+	httpServer *http.Server
+}
+
+func NewCatalogue() *Catalogue {
+	return &Catalogue{
+		entries: make(map[string]CatalogueEntry),
+		servers: make(map[string]*Server),
+	}
+}
+
+// Add registers a chain server. Name, EvmChainID and SubnetID come from entry;
+// the endpoint paths are filled in from the blockchain ID.
+func (c *Catalogue) Add(server *Server, entry CatalogueEntry) {
+	blockchainID := server.ChainID()
+	entry.Indexer = indexerPrefix(blockchainID) + "ws"
+	entry.Info = indexerPrefix(blockchainID) + "info"
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[blockchainID] = entry
+	c.servers[blockchainID] = server
+}
+
+// SetRoot additionally serves one chain's /info and /ws at the root path
+func (c *Catalogue) SetRoot(server *Server) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.root = server
+}
+
+func indexerPrefix(blockchainID string) string {
+	return "/indexer/" + blockchainID + "/"
+}
+
+// Handler returns the combined router
+func (c *Catalogue) Handler() http.Handler {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /chains", c.handleChains)
+	for blockchainID, server := range c.servers {
+		prefix := indexerPrefix(blockchainID)
+		mux.Handle(prefix, http.StripPrefix(prefix[:len(prefix)-1], server.Handler()))
+	}
+	if c.root != nil {
+		mux.Handle("/", c.root.Handler())
+	}
+	return mux
+}
+
+func (c *Catalogue) handleChains(w http.ResponseWriter, r *http.Request) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(c.entries); err != nil {
+		log.Printf("[Catalogue] Failed to encode /chains: %v", err)
+	}
+}
+
+// Start listens on addr and serves all registered chains
+func (c *Catalogue) Start(addr string) (string, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	c.httpServer = &http.Server{Handler: c.Handler()}
+	go func() {
+		if err := c.httpServer.Serve(listener); err != http.ErrServerClosed {
+			log.Printf("[Catalogue] HTTP server error: %v", err)
+		}
+	}()
+
+	log.Printf("[Catalogue] Listening on %s (%d chains)", addr, len(c.servers))
+	return addr, nil
+}
+
+// Stop shuts down the listener and every chain server
+func (c *Catalogue) Stop() {
+	if c.httpServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		c.httpServer.Shutdown(ctx)
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, server := range c.servers {
+		server.Stop()
+	}
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCatalogueRoutes(t *testing.T) {
+	cChain := NewServer(nil, "cchain")
+	cChain.UpdateLatestBlock(42)
+	other := NewServer(nil, "other")
+
+	c := NewCatalogue()
+	c.Add(cChain, CatalogueEntry{Name: "C-Chain", EvmChainID: 43114})
+	c.Add(other, CatalogueEntry{Name: "Other"})
+
+	ts := httptest.NewServer(c.Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/chains")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries map[string]CatalogueEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	got := entries["cchain"]
+	if got.Name != "C-Chain" || got.EvmChainID != 43114 || got.Indexer != "/indexer/cchain/ws" || got.Info != "/indexer/cchain/info" {
+		t.Fatalf("unexpected entry: %+v", got)
+	}
+
+	resp, err = http.Get(ts.URL + got.Info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), `"chainID":"cchain","latestBlock":42`) {
+		t.Fatalf("unexpected /info body: %s", body)
+	}
+
+	// Root routes are only mounted for single-chain deployments
+	resp, err = http.Get(ts.URL + "/info")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("root /info status = %d, want 404", resp.StatusCode)
+	}
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/containerman17/l1-data-tools/ingestion/evm/rpc/rpc"
+)
+
+// Filter selects which transactions a /ws subscription receives.
+// A transaction matches if any configured criterion matches it.
+// Blocks are always sent (with no transactions if nothing matched) so cursors advance.
+type Filter struct {
+	Addresses     map[string]bool // Log emitters, tx recipients and call frame from/to
+	Topics        map[string]bool // Any log topic position
+	TxFrom        map[string]bool
+	TxTo          map[string]bool
+	ExcludeTraces bool
+}
+
+// ParseFilter reads filter query parameters. Returns nil when none are set.
+//
+//	addresses=0xa,0xb  topics=0xddf2...  txFrom=0x..  txTo=0x..  traces=false
+func ParseFilter(q url.Values) (*Filter, error) {
+	f := &Filter{
+		Addresses: parseHexSet(q.Get("addresses")),
+		Topics:    parseHexSet(q.Get("topics")),
+		TxFrom:    parseHexSet(q.Get("txFrom")),
+		TxTo:      parseHexSet(q.Get("txTo")),
+	}
+	if v := q.Get("traces"); v != "" {
+		include, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid traces parameter: %w", err)
+		}
+		f.ExcludeTraces = !include
+	}
+
+	if !f.hasCriteria() && !f.ExcludeTraces {
+		return nil, nil
+	}
+	return f, nil
+}
+
+func parseHexSet(v string) map[string]bool {
+	if v == "" {
+		return nil
+	}
+	set := make(map[string]bool)
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			set[item] = true
+		}
+	}
+	return set
+}
+
+func (f *Filter) hasCriteria() bool {
+	return len(f.Addresses) > 0 || len(f.Topics) > 0 || len(f.TxFrom) > 0 || len(f.TxTo) > 0
+}
+
+func (f *Filter) hasLogCriteria() bool {
+	return len(f.Addresses) > 0 || len(f.Topics) > 0
+}
+
+func (f *Filter) matchLog(l *rpc.Log) bool {
+	if len(f.Addresses) > 0 && !f.Addresses[strings.ToLower(l.Address)] {
+		return false
+	}
+	if len(f.Topics) == 0 {
+		return true
+	}
+	for _, topic := range l.Topics {
+		if f.Topics[strings.ToLower(topic)] {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Filter) matchCall(c *rpc.CallTrace) bool {
+	if f.Addresses[strings.ToLower(c.From)] || f.Addresses[strings.ToLower(c.To)] {
+		return true
+	}
+	for i := range c.Calls {
+		if f.matchCall(&c.Calls[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// pruneCall keeps only frames that touch Addresses, plus their ancestors
+func (f *Filter) pruneCall(c rpc.CallTrace) rpc.CallTrace {
+	var kept []rpc.CallTrace
+	for i := range c.Calls {
+		if f.matchCall(&c.Calls[i]) {
+			kept = append(kept, f.pruneCall(c.Calls[i]))
+		}
+	}
+	c.Calls = kept
+	return c
+}
+
+// Project returns a copy of nb containing only matching transactions with their
+// receipts, matching logs and relevant call frames
+func (f *Filter) Project(nb *rpc.NormalizedBlock) *rpc.NormalizedBlock {
+	out := &rpc.NormalizedBlock{
+		Block:    nb.Block,
+		Receipts: []rpc.Receipt{},
+		Traces:   []rpc.TraceResultOptional{},
+	}
+	out.Block.Transactions = []rpc.Transaction{}
+
+	for i, tx := range nb.Block.Transactions {
+		var receipt *rpc.Receipt
+		if i < len(nb.Receipts) {
+			receipt = &nb.Receipts[i]
+		}
+		var trace *rpc.TraceResultOptional
+		if i < len(nb.Traces) {
+			trace = &nb.Traces[i]
+		}
+
+		// Logs that satisfy the log criteria (all logs if there are none)
+		var logs []rpc.Log
+		logMatched := false
+		if receipt != nil {
+			for j := range receipt.Logs {
+				if !f.hasLogCriteria() || f.matchLog(&receipt.Logs[j]) {
+					logs = append(logs, receipt.Logs[j])
+					logMatched = logMatched || f.hasLogCriteria()
+				}
+			}
+		}
+		callMatched := len(f.Addresses) > 0 && trace != nil && trace.Result != nil && f.matchCall(trace.Result)
+
+		matched := !f.hasCriteria() ||
+			f.TxFrom[strings.ToLower(tx.From)] ||
+			f.TxTo[strings.ToLower(tx.To)] ||
+			f.Addresses[strings.ToLower(tx.To)] ||
+			logMatched || callMatched
+		if !matched {
+			continue
+		}
+
+		out.Block.Transactions = append(out.Block.Transactions, tx)
+		if receipt != nil {
+			r := *receipt
+			r.Logs = logs
+			if r.Logs == nil {
+				r.Logs = []rpc.Log{}
+			}
+			out.Receipts = append(out.Receipts, r)
+		}
+		if trace != nil && !f.ExcludeTraces {
+			t := *trace
+			if t.Result != nil && len(f.Addresses) > 0 {
+				pruned := f.pruneCall(*t.Result)
+				t.Result = &pruned
+			}
+			out.Traces = append(out.Traces, t)
+		}
+	}
+
+	return out
+}
+
+// ProjectJSON applies Project to a stored block's JSON
+func (f *Filter) ProjectJSON(data []byte) ([]byte, error) {
+	var nb rpc.NormalizedBlock
+	if err := json.Unmarshal(data, &nb); err != nil {
+		return nil, fmt.Errorf("unmarshal block: %w", err)
+	}
+	return json.Marshal(f.Project(&nb))
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/containerman17/l1-data-tools/ingestion/evm/rpc/rpc"
+)
+
+const (
+	tokenAddr = "0x00000000000000000000000000000000000000aa"
+	otherAddr = "0x00000000000000000000000000000000000000bb"
+	userAddr  = "0x00000000000000000000000000000000000000cc"
+	transfer  = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+)
+
+func testBlock() *rpc.NormalizedBlock {
+	return &rpc.NormalizedBlock{
+		Block: rpc.Block{
+			Number: "0x10",
+			Transactions: []rpc.Transaction{
+				{Hash: "0x01", From: userAddr, To: otherAddr},
+				{Hash: "0x02", From: userAddr, To: otherAddr},
+			},
+		},
+		Receipts: []rpc.Receipt{
+			{TransactionHash: "0x01", Logs: []rpc.Log{{Address: otherAddr, Topics: []string{transfer}}}},
+			{TransactionHash: "0x02", Logs: []rpc.Log{
+				{Address: otherAddr, Topics: []string{transfer}},
+				{Address: "0x00000000000000000000000000000000000000AA", Topics: []string{transfer}},
+			}},
+		},
+		Traces: []rpc.TraceResultOptional{
+			{TxHash: "0x01", Result: &rpc.CallTrace{From: userAddr, To: otherAddr}},
+			{TxHash: "0x02", Result: &rpc.CallTrace{From: userAddr, To: otherAddr, Calls: []rpc.CallTrace{
+				{From: otherAddr, To: userAddr},
+				{From: otherAddr, To: tokenAddr, Calls: []rpc.CallTrace{{From: tokenAddr, To: userAddr}}},
+			}}},
+		},
+	}
+}
+
+func TestParseFilterEmpty(t *testing.T) {
+	f, err := ParseFilter(url.Values{"from": {"5"}})
+	if err != nil || f != nil {
+		t.Fatalf("ParseFilter() = %v, %v; want nil, nil", f, err)
+	}
+	if _, err := ParseFilter(url.Values{"traces": {"nope"}}); err == nil {
+		t.Fatal("expected error for invalid traces value")
+	}
+}
+
+func TestProjectByAddress(t *testing.T) {
+	f, err := ParseFilter(url.Values{"addresses": {tokenAddr}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := f.Project(testBlock())
+	if len(out.Block.Transactions) != 1 || out.Block.Transactions[0].Hash != "0x02" {
+		t.Fatalf("transactions = %+v; want only 0x02", out.Block.Transactions)
+	}
+	if logs := out.Receipts[0].Logs; len(logs) != 1 {
+		t.Fatalf("logs = %+v; want only the token log", logs)
+	}
+	calls := out.Traces[0].Result.Calls
+	if len(calls) != 1 || calls[0].To != tokenAddr || len(calls[0].Calls) != 1 {
+		t.Fatalf("calls = %+v; want only the token subtree", calls)
+	}
+}
+
+func TestProjectNoMatchKeepsBlock(t *testing.T) {
+	f, _ := ParseFilter(url.Values{"txFrom": {tokenAddr}})
+
+	out := f.Project(testBlock())
+	if out.Block.Number != "0x10" {
+		t.Fatalf("block number = %s", out.Block.Number)
+	}
+	if len(out.Block.Transactions) != 0 || len(out.Receipts) != 0 || len(out.Traces) != 0 {
+		t.Fatalf("expected empty block, got %+v", out)
+	}
+}
+
+func TestProjectExcludeTraces(t *testing.T) {
+	f, _ := ParseFilter(url.Values{"traces": {"false"}})
+
+	out := f.Project(testBlock())
+	if len(out.Block.Transactions) != 2 || len(out.Receipts) != 2 {
+		t.Fatalf("expected all transactions and receipts, got %d/%d", len(out.Block.Transactions), len(out.Receipts))
+	}
+	if len(out.Traces) != 0 {
+		t.Fatalf("traces = %+v; want none", out.Traces)
+	}
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/containerman17/l1-data-tools/ingestion/evm/rpc/consts"
+	"github.com/containerman17/l1-data-tools/ingestion/evm/rpc/storage"
+)
+
+// contentETag is a strong ETag derived from the response body
+func contentETag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// serveZstd writes a zstd JSONL body with Content-Length, ETag and Range support
+func serveZstd(w http.ResponseWriter, r *http.Request, name string, data []byte) {
+	w.Header().Set("Content-Type", "application/zstd")
+	w.Header().Set("ETag", contentETag(data))
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(data))
+}
+
+// handleBatch serves one compacted batch as stored: GET /batches/{start}
+func (s *Server) handleBatch(w http.ResponseWriter, r *http.Request) {
+	start, err := strconv.ParseUint(r.PathValue("start"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid batch start", http.StatusBadRequest)
+		return
+	}
+	if start == 0 || storage.BatchStart(start) != start {
+		http.Error(w, fmt.Sprintf("batch start must be 1 + a multiple of %d", storage.BatchSize), http.StatusBadRequest)
+		return
+	}
+
+	data, err := s.store.GetBatchCompressed(start)
+	if err != nil || len(data) == 0 {
+		http.Error(w, "batch not available", http.StatusNotFound)
+		return
+	}
+
+	// Compacted batches never change (except through a reorg rollback, which changes the ETag)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	serveZstd(w, r, fmt.Sprintf("batch-%d.jsonl.zst", start), data)
+}
+
+// handleBlocks serves an exact block range as zstd JSONL: GET /blocks?from=&to=
+// Fully covered batches are passed through as stored; edges and uncompacted
+// blocks are re-encoded. The body is a concatenation of zstd frames.
+func (s *Server) handleBlocks(w http.ResponseWriter, r *http.Request) {
+	from, errFrom := strconv.ParseUint(r.URL.Query().Get("from"), 10, 64)
+	to, errTo := strconv.ParseUint(r.URL.Query().Get("to"), 10, 64)
+	if errFrom != nil || errTo != nil || from == 0 || to < from {
+		http.Error(w, "from and to are required, 1 <= from <= to", http.StatusBadRequest)
+		return
+	}
+	if to-from+1 > consts.ServerMaxRangeBlocks {
+		http.Error(w, fmt.Sprintf("range too large: max %d blocks", consts.ServerMaxRangeBlocks), http.StatusBadRequest)
+		return
+	}
+	if latest := s.latestBlock.Load(); to > latest {
+		http.Error(w, fmt.Sprintf("range not available: latest block is %d", latest), http.StatusNotFound)
+		return
+	}
+
+	body, err := s.readRange(from, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	serveZstd(w, r, fmt.Sprintf("blocks-%d-%d.jsonl.zst", from, to), body)
+}
+
+// readRange assembles zstd JSONL for blocks [from, to]
+func (s *Server) readRange(from, to uint64) ([]byte, error) {
+	var out []byte
+	var pending []byte // uncompressed JSONL not yet flushed as a frame
+
+	flush := func() {
+		if len(pending) > 0 {
+			out = s.zstdEnc.EncodeAll(pending, out)
+			pending = nil
+		}
+	}
+
+	for cur := from; cur <= to; {
+		if data, err := s.store.GetBlock(cur); err == nil && len(data) > 0 {
+			pending = append(pending, data...)
+			pending = append(pending, '\n')
+			cur++
+			continue
+		}
+
+		batchStart := storage.BatchStart(cur)
+		batchEnd := storage.BatchEnd(batchStart)
+		compressed, err := s.store.GetBatchCompressed(batchStart)
+		if err != nil || len(compressed) == 0 {
+			return nil, fmt.Errorf("block %d not available", cur)
+		}
+
+		if cur == batchStart && batchEnd <= to {
+			// Whole batch requested - pass through as stored
+			flush()
+			out = append(out, compressed...)
+			cur = batchEnd + 1
+			continue
+		}
+
+		blocks, err := storage.DecompressBlocks(compressed)
+		if err != nil {
+			return nil, fmt.Errorf("decompress batch %d: %w", batchStart, err)
+		}
+		for ; cur <= to && cur <= batchEnd; cur++ {
+			idx := cur - batchStart
+			if idx >= uint64(len(blocks)) {
+				return nil, fmt.Errorf("block %d missing from batch %d", cur, batchStart)
+			}
+			pending = append(pending, blocks[idx]...)
+			pending = append(pending, '\n')
+		}
+	}
+	flush()
+
+	return out, nil
+}
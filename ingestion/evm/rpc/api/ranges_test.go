@@ -0,0 +1,130 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containerman17/l1-data-tools/ingestion/evm/rpc/storage"
+	"github.com/klauspost/compress/zstd"
+)
+
+// rangeStore has batches for 1..200 and individual blocks 201..250
+func rangeStore(t *testing.T) *storage.PebbleStorage {
+	t.Helper()
+	s, err := storage.NewPebbleStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	for start := uint64(1); start < 200; start += storage.BatchSize {
+		var blocks [][]byte
+		for n := start; n <= storage.BatchEnd(start); n++ {
+			blocks = append(blocks, []byte(fmt.Sprintf(`{"n":%d}`, n)))
+		}
+		compressed, err := storage.CompressBlocks(blocks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SaveBatch(start, storage.BatchEnd(start), compressed); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for n := uint64(201); n <= 250; n++ {
+		if err := s.SaveBlock(n, []byte(fmt.Sprintf(`{"n":%d}`, n))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+func get(t *testing.T, url string, header http.Header) (*http.Response, []byte) {
+	t.Helper()
+	req, _ := http.NewRequest("GET", url, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	return resp, body
+}
+
+func TestBlocksRange(t *testing.T) {
+	srv := NewServer(rangeStore(t), "test")
+	srv.UpdateLatestBlock(250)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	resp, body := get(t, ts.URL+"/blocks?from=50&to=220", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d: %s", resp.StatusCode, body)
+	}
+	if resp.Header.Get("ETag") == "" || resp.ContentLength != int64(len(body)) {
+		t.Fatalf("missing ETag or Content-Length: %v", resp.Header)
+	}
+
+	dec, _ := zstd.NewReader(nil)
+	defer dec.Close()
+	jsonl, err := dec.DecodeAll(body, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := bytes.Split(bytes.TrimSuffix(jsonl, []byte("\n")), []byte("\n"))
+	if len(lines) != 171 {
+		t.Fatalf("got %d blocks, want 171", len(lines))
+	}
+	for i, line := range lines {
+		if want := fmt.Sprintf(`{"n":%d}`, 50+i); string(line) != want {
+			t.Fatalf("line %d = %s, want %s", i, line, want)
+		}
+	}
+
+	// Resume a partial download
+	resp, part := get(t, ts.URL+"/blocks?from=50&to=220", http.Header{"Range": {"bytes=10-"}})
+	if resp.StatusCode != http.StatusPartialContent || !bytes.Equal(part, body[10:]) {
+		t.Fatalf("range status = %d, %d bytes", resp.StatusCode, len(part))
+	}
+
+	for _, q := range []string{"from=0&to=10", "from=10&to=5", "from=1&to=20000", "to=10"} {
+		if resp, _ := get(t, ts.URL+"/blocks?"+q, nil); resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want 400", q, resp.StatusCode)
+		}
+	}
+	if resp, _ := get(t, ts.URL+"/blocks?from=240&to=260", nil); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("beyond tip: status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestBatchDownload(t *testing.T) {
+	store := rangeStore(t)
+	srv := NewServer(store, "test")
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	resp, body := get(t, ts.URL+"/batches/101", nil)
+	stored, _ := store.GetBatchCompressed(101)
+	if resp.StatusCode != http.StatusOK || !bytes.Equal(body, stored) {
+		t.Fatalf("status = %d, body matches stored = %v", resp.StatusCode, bytes.Equal(body, stored))
+	}
+
+	etag := resp.Header.Get("ETag")
+	resp, _ = get(t, ts.URL+"/batches/101", http.Header{"If-None-Match": {etag}})
+	if resp.StatusCode != http.StatusNotModified {
+		t.Fatalf("If-None-Match status = %d, want 304", resp.StatusCode)
+	}
+
+	if resp, _ := get(t, ts.URL+"/batches/50", nil); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unaligned status = %d, want 400", resp.StatusCode)
+	}
+	if resp, _ := get(t, ts.URL+"/batches/201", nil); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("uncompacted status = %d, want 404", resp.StatusCode)
+	}
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net"
@@ -12,6 +13,7 @@ import (
 	"time"
 
 	"github.com/containerman17/l1-data-tools/ingestion/evm/rpc/consts"
+	"github.com/containerman17/l1-data-tools/ingestion/evm/rpc/rpc"
 	"github.com/containerman17/l1-data-tools/ingestion/evm/rpc/storage"
 
 	"github.com/gorilla/websocket"
@@ -26,9 +28,29 @@ type Server struct {
 	cancel      context.CancelFunc
 	zstdEnc     *zstd.Encoder
 	mu          sync.RWMutex
-	chainID     string // 32-byte Avalanche chain ID (base58)
+	chainID     string            // 32-byte Avalanche chain ID (base58)
+	traceMode   string            // What stored blocks carry in "traces", reported by /info
+	coverage    *storage.Coverage // Stored ranges reported by /info (nil: contiguous store)
+
+	// Rollback history, guarded by mu. Connections compare rollbackSeq
+	// against the last one they saw and rewind if they are past the fork.
+	rollbackSeq uint64
+	rollbacks   []rollbackEvent
+
+	// Live tip: recently published blocks and a channel closed on each publish
+	tipMu     sync.Mutex
+	tipCh     chan struct{}
+	tipBlocks map[uint64]tipBlock
+}
+
+type rollbackEvent struct {
+	seq     uint64
+	toBlock uint64
 }
 
+// maxRollbackHistory bounds how many rollbacks a slow connection can catch up on
+const maxRollbackHistory = 16
+
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 64 * 1024,
@@ -39,11 +61,14 @@ func NewServer(store storage.Storage, chainID string) *Server {
 	ctx, cancel := context.WithCancel(context.Background())
 	enc, _ := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedFastest))
 	return &Server{
-		store:   store,
-		ctx:     ctx,
-		cancel:  cancel,
-		zstdEnc: enc,
-		chainID: chainID,
+		store:     store,
+		ctx:       ctx,
+		cancel:    cancel,
+		zstdEnc:   enc,
+		chainID:   chainID,
+		tipCh:     make(chan struct{}),
+		tipBlocks: make(map[uint64]tipBlock),
+		traceMode: string(rpc.TraceModeCall),
 	}
 }
 
@@ -65,17 +90,77 @@ func (s *Server) GetLatestBlock() uint64 {
 	return s.latestBlock.Load()
 }
 
-func (s *Server) Start(addr string) (string, error) {
+// Rollback lowers the latest block to toBlock and tells every connected
+// consumer that has already been sent blocks above it to rewind.
+// Storage must already be rolled back when this is called.
+func (s *Server) Rollback(toBlock uint64) {
+	s.mu.Lock()
+	s.rollbackSeq++
+	s.rollbacks = append(s.rollbacks, rollbackEvent{seq: s.rollbackSeq, toBlock: toBlock})
+	if len(s.rollbacks) > maxRollbackHistory {
+		s.rollbacks = s.rollbacks[len(s.rollbacks)-maxRollbackHistory:]
+	}
+	s.mu.Unlock()
+
+	s.latestBlock.Store(toBlock)
+	s.dropTipBlocks(toBlock)
+	s.notifyTip()
+	log.Printf("[Server] Rolled back to block %d", toBlock)
+}
+
+// pendingRollback returns the lowest rollback target published after seenSeq
+func (s *Server) pendingRollback(seenSeq uint64) (toBlock uint64, seq uint64, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seq = s.rollbackSeq
+	if seq == seenSeq {
+		return 0, seq, false
+	}
+	for _, ev := range s.rollbacks {
+		if ev.seq <= seenSeq {
+			continue
+		}
+		if !ok || ev.toBlock < toBlock {
+			toBlock = ev.toBlock
+			ok = true
+		}
+	}
+	return toBlock, seq, ok
+}
+
+// Handler returns the chain's routes for mounting under a prefix
+func (s *Server) Handler() http.Handler {
 	mux := http.NewServeMux()
 	mux.HandleFunc("GET /info", s.handleInfo)
 	mux.HandleFunc("GET /ws", s.handleWS)
+	mux.HandleFunc("GET /blocks", s.handleBlocks)
+	mux.HandleFunc("GET /batches/{start}", s.handleBatch)
+	return mux
+}
+
+// SetTraceMode records the trace mode of stored blocks for /info
+func (s *Server) SetTraceMode(mode string) {
+	s.traceMode = mode
+}
 
+// SetCoverage reports the store's block ranges in /info
+func (s *Server) SetCoverage(c *storage.Coverage) {
+	s.coverage = c
+}
+
+// ChainID returns the Avalanche chain ID this server serves
+func (s *Server) ChainID() string {
+	return s.chainID
+}
+
+func (s *Server) Start(addr string) (string, error) {
 	listener, err := net.Listen("tcp", addr)
 	if err != nil {
 		return "", fmt.Errorf("failed to listen on %s: %w", addr, err)
 	}
 
-	s.httpServer = &http.Server{Handler: mux}
+	s.httpServer = &http.Server{Handler: s.Handler()}
 	go func() {
 		if err := s.httpServer.Serve(listener); err != http.ErrServerClosed {
 			log.Printf("[Server] HTTP server error: %v", err)
@@ -95,10 +180,26 @@ func (s *Server) Stop() {
 	}
 }
 
+// infoResponse is the /info body
+type infoResponse struct {
+	ChainID     string               `json:"chainID"`
+	LatestBlock uint64               `json:"latestBlock"`
+	TraceMode   string               `json:"traceMode"`
+	Ranges      []storage.BlockRange `json:"ranges,omitempty"` // Stored ranges; blocks outside them are not served yet
+}
+
 // handleInfo returns chain info as JSON
 func (s *Server) handleInfo(w http.ResponseWriter, r *http.Request) {
+	info := infoResponse{
+		ChainID:     s.chainID,
+		LatestBlock: s.latestBlock.Load(),
+		TraceMode:   s.traceMode,
+	}
+	if s.coverage != nil {
+		info.Ranges = s.coverage.Ranges()
+	}
 	w.Header().Set("Content-Type", "application/json")
-	fmt.Fprintf(w, `{"chainID":"%s","latestBlock":%d}`, s.chainID, s.latestBlock.Load())
+	json.NewEncoder(w).Encode(info)
 }
 
 // handleWS upgrades to WebSocket and streams blocks
@@ -118,6 +219,12 @@ func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
 		fromBlock = 1
 	}
 
+	filter, err := ParseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("[Server] WebSocket upgrade failed: %v", err)
@@ -125,29 +232,65 @@ func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
 	}
 	defer conn.Close()
 
-	log.Printf("[Server] Client connected from block %d", fromBlock)
+	log.Printf("[Server] Client connected from block %d (filtered: %v)", fromBlock, filter != nil)
 
-	if err := s.streamBlocks(conn, fromBlock); err != nil {
+	if err := s.streamBlocks(conn, fromBlock, filter); err != nil {
 		log.Printf("[Server] Client stream ended: %v", err)
 	}
 }
 
 // streamBlocks streams blocks over WebSocket
 // Binary frames: zstd(NormalizedBlock\n...) - 1 to 100 blocks per frame
-func (s *Server) streamBlocks(conn *websocket.Conn, fromBlock uint64) error {
+// Text frames: rpc.ControlFrame JSON (rollback notifications)
+// With a filter, every block is projected and re-compressed before sending
+func (s *Server) streamBlocks(conn *websocket.Conn, fromBlock uint64, filter *Filter) error {
 	ctx := s.ctx
 	currentBlock := fromBlock
 
+	s.mu.RLock()
+	seenRollback := s.rollbackSeq
+	s.mu.RUnlock()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		default:
 		}
+		tipCh := s.tipSignal()
+
+		// Rewind if blocks we already sent were rolled back
+		if toBlock, seq, ok := s.pendingRollback(seenRollback); ok {
+			seenRollback = seq
+			if currentBlock > fromBlock && currentBlock > toBlock+1 {
+				frame, _ := json.Marshal(rpc.ControlFrame{Type: rpc.ControlFrameRollback, ToBlock: toBlock})
+				if err := conn.WriteMessage(websocket.TextMessage, frame); err != nil {
+					return err
+				}
+				currentBlock = toBlock + 1
+			}
+		}
+
+		// 1. Freshly published block, already compressed
+		if filter == nil {
+			if tb, ok := s.getTipBlock(currentBlock); ok {
+				if err := conn.WriteMessage(websocket.BinaryMessage, tb.compressed); err != nil {
+					return err
+				}
+				s.observeTipDelivery(tb)
+				currentBlock++
+				continue
+			}
+		}
 
-		// 1. Try single block from local store
+		// 2. Try single block from local store
 		data, err := s.store.GetBlock(currentBlock)
 		if err == nil && len(data) > 0 {
+			if filter != nil {
+				if data, err = filter.ProjectJSON(data); err != nil {
+					return fmt.Errorf("project block %d: %w", currentBlock, err)
+				}
+			}
 			compressed := s.zstdEnc.EncodeAll(append(data, '\n'), nil)
 			if err := conn.WriteMessage(websocket.BinaryMessage, compressed); err != nil {
 				return err
@@ -156,10 +299,15 @@ func (s *Server) streamBlocks(conn *websocket.Conn, fromBlock uint64) error {
 			continue
 		}
 
-		// 2. Try compressed batch from local store
+		// 3. Try compressed batch from local store
 		batchStart := storage.BatchStart(currentBlock)
 		batchData, err := s.store.GetBatchCompressed(batchStart)
 		if err == nil && len(batchData) > 0 {
+			if filter != nil {
+				if batchData, err = s.projectBatch(batchData, filter); err != nil {
+					return fmt.Errorf("project batch %d: %w", batchStart, err)
+				}
+			}
 			// Send as-is (already zstd compressed JSONL)
 			if err := conn.WriteMessage(websocket.BinaryMessage, batchData); err != nil {
 				return err
@@ -168,7 +316,32 @@ func (s *Server) streamBlocks(conn *websocket.Conn, fromBlock uint64) error {
 			continue
 		}
 
-		// Block not available - we're at the tip, wait
-		time.Sleep(consts.ServerTipPollInterval)
+		// Block not available - we're at the tip or in a gap backfill has not
+		// reached yet. Wait for the next publish or backfilled batch; never skip
+		// ahead. The timer only covers writers that save blocks silently.
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tipCh:
+		case <-time.After(consts.ServerTipPollInterval):
+		}
+	}
+}
+
+// projectBatch applies filter to every block of a compressed batch
+func (s *Server) projectBatch(batchData []byte, filter *Filter) ([]byte, error) {
+	blocks, err := storage.DecompressBlocks(batchData)
+	if err != nil {
+		return nil, err
+	}
+	var jsonl []byte
+	for _, block := range blocks {
+		projected, err := filter.ProjectJSON(block)
+		if err != nil {
+			return nil, err
+		}
+		jsonl = append(jsonl, projected...)
+		jsonl = append(jsonl, '\n')
 	}
+	return s.zstdEnc.EncodeAll(jsonl, nil), nil
 }
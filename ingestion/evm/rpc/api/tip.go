@@ -0,0 +1,80 @@
+package api
+
+import (
+	"time"
+
+	"github.com/containerman17/l1-data-tools/ingestion/evm/rpc/consts"
+	"github.com/containerman17/l1-data-tools/ingestion/evm/rpc/metrics"
+)
+
+// tipBlock is a freshly published block, compressed once for all connections
+type tipBlock struct {
+	compressed  []byte
+	publishedAt time.Time
+}
+
+// PublishBlock announces a block that was just saved to storage.
+// The block is compressed once and every connection waiting at the tip is woken.
+func (s *Server) PublishBlock(blockNum uint64, data []byte) {
+	compressed := s.zstdEnc.EncodeAll(append(data[:len(data):len(data)], '\n'), nil)
+
+	s.tipMu.Lock()
+	s.tipBlocks[blockNum] = tipBlock{compressed: compressed, publishedAt: time.Now()}
+	if blockNum > consts.ServerTipCacheBlocks {
+		for n := range s.tipBlocks {
+			if n <= blockNum-consts.ServerTipCacheBlocks {
+				delete(s.tipBlocks, n)
+			}
+		}
+	}
+	s.tipMu.Unlock()
+
+	s.UpdateLatestBlock(blockNum)
+	s.notifyTip()
+}
+
+// NotifyStored wakes waiting connections after blocks were saved without being
+// published (backfill lanes), so consumers blocked on a gap resume promptly
+func (s *Server) NotifyStored() {
+	s.notifyTip()
+}
+
+// notifyTip wakes every connection blocked in waitTip
+func (s *Server) notifyTip() {
+	s.tipMu.Lock()
+	close(s.tipCh)
+	s.tipCh = make(chan struct{})
+	s.tipMu.Unlock()
+}
+
+// tipSignal returns a channel closed on the next publish.
+// Take it before checking storage so a publish in between is not missed.
+func (s *Server) tipSignal() <-chan struct{} {
+	s.tipMu.Lock()
+	defer s.tipMu.Unlock()
+	return s.tipCh
+}
+
+// getTipBlock returns a published block's compressed frame if still cached
+func (s *Server) getTipBlock(blockNum uint64) (tipBlock, bool) {
+	s.tipMu.Lock()
+	defer s.tipMu.Unlock()
+	b, ok := s.tipBlocks[blockNum]
+	return b, ok
+}
+
+// dropTipBlocks forgets published blocks above toBlock (rollback)
+func (s *Server) dropTipBlocks(toBlock uint64) {
+	s.tipMu.Lock()
+	for n := range s.tipBlocks {
+		if n > toBlock {
+			delete(s.tipBlocks, n)
+		}
+	}
+	s.tipMu.Unlock()
+}
+
+// observeTipDelivery records publish-to-send latency of a live block
+func (s *Server) observeTipDelivery(b tipBlock) {
+	metrics.TipDeliverySeconds.WithLabelValues(s.chainID).Observe(time.Since(b.publishedAt).Seconds())
+}
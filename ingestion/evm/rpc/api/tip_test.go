@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containerman17/l1-data-tools/ingestion/evm/rpc/consts"
+	"github.com/containerman17/l1-data-tools/ingestion/evm/rpc/storage"
+	"github.com/gorilla/websocket"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestPublishWakesTipFollowers(t *testing.T) {
+	store, err := storage.NewPebbleStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	srv := NewServer(store, "test")
+	defer srv.Stop()
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws?from=1"
+	var conns []*websocket.Conn
+	for i := 0; i < 3; i++ {
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		conns = append(conns, conn)
+	}
+
+	// Let the connections reach the tip and block
+	time.Sleep(100 * time.Millisecond)
+	srv.PublishBlock(1, []byte(`{"n":1}`))
+
+	dec, _ := zstd.NewReader(nil)
+	defer dec.Close()
+	for _, conn := range conns {
+		// Well under the fallback poll interval
+		conn.SetReadDeadline(time.Now().Add(consts.ServerTipPollInterval / 2))
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("no block delivered: %v", err)
+		}
+		data, err := dec.DecodeAll(msg, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "{\"n\":1}\n" {
+			t.Fatalf("got %q", data)
+		}
+	}
+}
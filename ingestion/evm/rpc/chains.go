@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/containerman17/l1-data-tools/ingestion/evm/rpc/consts"
+	"github.com/containerman17/l1-data-tools/ingestion/evm/rpc/rpc"
+)
+
+// ChainSpec is one chain served by the sink
+type ChainSpec struct {
+	BlockchainID   string   `json:"blockchainId"`
+	Name           string   `json:"name"`
+	SubnetID       string   `json:"subnetId,omitempty"`
+	RPCURL         string   `json:"rpcUrl"`
+	FallbackURLs   []string `json:"fallbackRpcUrls,omitempty"` // Extra upstream nodes for failover
+	HedgeDelayMs   int      `json:"hedgeDelayMs,omitempty"`    // Default: RPC_HEDGE_DELAY_MS (0 = no hedging)
+	PebblePath     string   `json:"pebblePath,omitempty"`      // Default: {PEBBLE_PATH}/{blockchainId}
+	MaxParallelism int      `json:"maxParallelism,omitempty"`  // Default: MAX_PARALLELISM
+	Lookahead      int      `json:"lookahead,omitempty"`       // Default: LOOKAHEAD
+	TraceMode      string   `json:"traceMode,omitempty"`       // Default: TRACE_MODE (auto)
+	BackfillLanes  int      `json:"backfillLanes,omitempty"`   // Default: BACKFILL_LANES (negative disables)
+
+	evmChainID uint64 // Filled from eth_chainId at startup
+}
+
+// loadChains reads the chain list from the CHAINS_CONFIG JSON file, or builds a
+// single chain from RPC_URL/CHAIN_ID when CHAINS_CONFIG is not set
+func loadChains() ([]*ChainSpec, error) {
+	pebblePath := getEnvOrDefault("PEBBLE_PATH", "./data/pebble")
+	maxParallelism := getEnvIntOrDefault("MAX_PARALLELISM", consts.RPCDefaultMaxParallelism)
+	lookahead := getEnvIntOrDefault("LOOKAHEAD", 100)
+	hedgeDelayMs := getEnvIntOrDefault("RPC_HEDGE_DELAY_MS", 0)
+	backfillLanes := getEnvIntOrDefault("BACKFILL_LANES", consts.IngestionDefaultBackfillLanes)
+	traceMode := getEnvOrDefault("TRACE_MODE", string(rpc.TraceModeAuto))
+	if _, err := rpc.ParseTraceMode(traceMode); err != nil {
+		return nil, fmt.Errorf("TRACE_MODE: %w", err)
+	}
+
+	configPath := os.Getenv("CHAINS_CONFIG")
+	if configPath == "" {
+		rpcURL := os.Getenv("RPC_URL")
+		if rpcURL == "" {
+			return nil, fmt.Errorf("RPC_URL environment variable is required (or CHAINS_CONFIG)")
+		}
+		chainID := os.Getenv("CHAIN_ID")
+		if chainID == "" {
+			return nil, fmt.Errorf("CHAIN_ID environment variable is required (32-byte Avalanche chain ID)")
+		}
+		return []*ChainSpec{{
+			BlockchainID:   chainID,
+			Name:           getEnvOrDefault("CHAIN_NAME", chainID),
+			SubnetID:       os.Getenv("SUBNET_ID"),
+			RPCURL:         rpcURL,
+			FallbackURLs:   splitList(os.Getenv("RPC_FALLBACK_URLS")),
+			HedgeDelayMs:   hedgeDelayMs,
+			PebblePath:     pebblePath,
+			MaxParallelism: maxParallelism,
+			Lookahead:      lookahead,
+			TraceMode:      traceMode,
+			BackfillLanes:  backfillLanes,
+		}}, nil
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("read CHAINS_CONFIG: %w", err)
+	}
+	var chains []*ChainSpec
+	if err := json.Unmarshal(data, &chains); err != nil {
+		return nil, fmt.Errorf("parse CHAINS_CONFIG: %w", err)
+	}
+	if len(chains) == 0 {
+		return nil, fmt.Errorf("CHAINS_CONFIG %s lists no chains", configPath)
+	}
+
+	seen := make(map[string]bool)
+	for i, c := range chains {
+		if c.BlockchainID == "" || c.RPCURL == "" {
+			return nil, fmt.Errorf("CHAINS_CONFIG entry %d: blockchainId and rpcUrl are required", i)
+		}
+		if seen[c.BlockchainID] {
+			return nil, fmt.Errorf("CHAINS_CONFIG: duplicate blockchainId %s", c.BlockchainID)
+		}
+		seen[c.BlockchainID] = true
+
+		if c.Name == "" {
+			c.Name = c.BlockchainID
+		}
+		if c.PebblePath == "" {
+			c.PebblePath = filepath.Join(pebblePath, c.BlockchainID)
+		}
+		if c.MaxParallelism <= 0 {
+			c.MaxParallelism = maxParallelism
+		}
+		if c.Lookahead <= 0 {
+			c.Lookahead = lookahead
+		}
+		if c.HedgeDelayMs <= 0 {
+			c.HedgeDelayMs = hedgeDelayMs
+		}
+		if c.TraceMode == "" {
+			c.TraceMode = traceMode
+		}
+		if c.BackfillLanes == 0 {
+			c.BackfillLanes = backfillLanes
+		}
+		if _, err := rpc.ParseTraceMode(c.TraceMode); err != nil {
+			return nil, fmt.Errorf("CHAINS_CONFIG entry %d: %w", i, err)
+		}
+	}
+	return chains, nil
+}
+
+// selectChains returns the configured chains matching filter (blockchainId or name), or all when empty
+func selectChains(filter string) ([]*ChainSpec, error) {
+	chains, err := loadChains()
+	if err != nil {
+		return nil, err
+	}
+	if filter == "" {
+		return chains, nil
+	}
+	for _, cfg := range chains {
+		if cfg.BlockchainID == filter || cfg.Name == filter {
+			return []*ChainSpec{cfg}, nil
+		}
+	}
+	return nil, fmt.Errorf("chain %q is not configured", filter)
+}
+
+// splitList parses a comma-separated env value, dropping empty items
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -22,6 +22,15 @@ const (
 
 	// RPCMaxErrorsPerMinute - halve parallelism if exceeded
 	RPCMaxErrorsPerMinute = 10
+
+	// RPCMaxHeadLag - endpoints further behind the best known head are unhealthy
+	RPCMaxHeadLag = 5
+
+	// RPCMaxEndpointErrorRate - endpoints failing more than this share of requests are unhealthy
+	RPCMaxEndpointErrorRate = 0.2
+
+	// RPCProbeTimeout for eth_blockNumber health probes
+	RPCProbeTimeout = 5 * time.Second
 )
 
 // =============================================================================
@@ -56,6 +65,24 @@ const (
 
 	// FetcherDialTimeout for new connections
 	FetcherDialTimeout = 30 * time.Second
+
+	// FetcherMaxReorgDepth is how far back the fork point is searched after a parentHash mismatch
+	FetcherMaxReorgDepth = 256
+)
+
+// =============================================================================
+// Ingestion - Tip lane and backfill lanes
+// =============================================================================
+
+const (
+	// IngestionDefaultBackfillLanes if not specified in config (0 disables tip-first ingestion)
+	IngestionDefaultBackfillLanes = 2
+
+	// IngestionTipFirstMinGap - below this many missing blocks the tip lane just walks forward
+	IngestionTipFirstMinGap = 10000
+
+	// IngestionCoverageFlushInterval is how often stored ranges are persisted
+	IngestionCoverageFlushInterval = 5 * time.Second
 )
 
 // =============================================================================
@@ -71,6 +98,9 @@ const (
 
 	// StorageCompactionInterval is how often to check for compaction
 	StorageCompactionInterval = 3 * time.Second
+
+	// StorageArchiveSegmentBatches is compacted batches per archive segment file (100k blocks)
+	StorageArchiveSegmentBatches = 1000
 )
 
 // =============================================================================
@@ -84,6 +114,12 @@ const (
 	// MetricsListenAddr is the Prometheus metrics server address
 	MetricsListenAddr = ":9091"
 
-	// ServerTipPollInterval when waiting for new blocks at tip
-	ServerTipPollInterval = 50 * time.Millisecond
+	// ServerTipPollInterval is the fallback wakeup at tip when no block is published
+	ServerTipPollInterval = 1 * time.Second
+
+	// ServerTipCacheBlocks is how many published blocks are kept pre-compressed for tip followers
+	ServerTipCacheBlocks = 128
+
+	// ServerMaxRangeBlocks caps GET /blocks?from=&to= (held in memory per request)
+	ServerMaxRangeBlocks = 10000
 )
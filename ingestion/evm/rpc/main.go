@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -24,87 +25,125 @@ import (
 func main() {
 	_ = godotenv.Load() // Load .env if present
 
-	// Required: RPC URL and Chain ID
-	rpcURL := os.Getenv("RPC_URL")
-	if rpcURL == "" {
-		log.Fatal("RPC_URL environment variable is required")
-	}
-	chainID := os.Getenv("CHAIN_ID")
-	if chainID == "" {
-		log.Fatal("CHAIN_ID environment variable is required (32-byte Avalanche chain ID)")
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "verify":
+			os.Exit(runVerify(os.Args[2:]))
+		case "export":
+			os.Exit(runExport(os.Args[2:]))
+		case "import":
+			os.Exit(runImport(os.Args[2:]))
+		}
 	}
 
-	// Optional: other settings with defaults
-	pebblePath := getEnvOrDefault("PEBBLE_PATH", "./data/pebble")
 	serverAddr := getEnvOrDefault("SERVER_ADDR", consts.ServerListenAddr)
-	maxParallelism := getEnvIntOrDefault("MAX_PARALLELISM", consts.RPCDefaultMaxParallelism)
-	lookahead := getEnvIntOrDefault("LOOKAHEAD", 100)
+
+	chains, err := loadChains()
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	catalogue := api.NewCatalogue()
+	for _, cfg := range chains {
+		server, err := startChain(ctx, cfg)
+		if err != nil {
+			log.Fatalf("[%s] Failed to start chain: %v", cfg.Name, err)
+		}
+		catalogue.Add(server, api.CatalogueEntry{
+			Name:       cfg.Name,
+			EvmChainID: cfg.evmChainID,
+			SubnetID:   cfg.SubnetID,
+		})
+		// Single-chain deployments keep serving /info and /ws at the root
+		if len(chains) == 1 {
+			catalogue.SetRoot(server)
+		}
+	}
+
+	actualAddr, err := catalogue.Start(serverAddr)
+	if err != nil {
+		log.Fatalf("Failed to start server: %v", err)
+	}
+	log.Printf("Server listening on %s", actualAddr)
+
+	// Start metrics server
+	metrics.StartServer(consts.MetricsListenAddr)
+
+	// Block forever
+	select {}
+}
+
+// startChain opens the chain's storage and starts its fetcher, compactor and ingestion loop
+func startChain(ctx context.Context, cfg *ChainSpec) (*api.Server, error) {
 	// Fetch EVM chainID from RPC
-	evmChainID, err := fetchChainID(rpcURL)
+	evmChainID, err := fetchChainID(cfg.RPCURL)
 	if err != nil {
-		log.Fatalf("Failed to fetch chainID from RPC: %v", err)
+		return nil, fmt.Errorf("fetch chainID from RPC: %w", err)
 	}
-	log.Printf("Connected to EVM chain %d (Avalanche chain %s)", evmChainID, chainID)
+	cfg.evmChainID = evmChainID
+	log.Printf("[%s] Connected to EVM chain %d (Avalanche chain %s)", cfg.Name, evmChainID, cfg.BlockchainID)
 
-	// Initialize storage
-	store, err := storage.NewPebbleStorage(pebblePath)
+	// Initialize storage (never closed - lives for the whole process)
+	store, err := storage.NewPebbleStorage(cfg.PebblePath)
 	if err != nil {
-		log.Fatalf("Failed to open storage: %v", err)
+		return nil, fmt.Errorf("open storage: %w", err)
 	}
-	defer store.Close()
-	log.Printf("Storage opened at %s", pebblePath)
+	log.Printf("[%s] Storage opened at %s", cfg.Name, cfg.PebblePath)
 
-	// Initialize API server
-	server := api.NewServer(store, chainID)
+	server := api.NewServer(store, cfg.BlockchainID)
 
 	// Initialize metrics
 	chainLabel := fmt.Sprintf("chain-%d", evmChainID)
 	metrics.InitChain(chainLabel)
 
-	// Create controller and fetcher
-	chainCfg := rpc.ChainConfig{
-		ChainID:        evmChainID,
-		Name:           chainLabel,
-		URL:            rpcURL,
-		MaxParallelism: maxParallelism,
-	}
-	controller := rpc.NewController(chainCfg)
-
-	fetcher, err := rpc.NewFetcher(rpc.FetcherConfig{
-		Controller: controller,
-		ChainID:    evmChainID,
-		ChainName:  chainLabel,
-		Ctx:        ctx,
-	})
+	fetcher, err := newFetcher(ctx, cfg, chainLabel)
 	if err != nil {
-		log.Fatalf("Failed to create fetcher: %v", err)
+		store.Close()
+		return nil, err
 	}
+	server.SetTraceMode(string(fetcher.TraceMode()))
+	log.Printf("[%s] Trace mode: %s", cfg.Name, fetcher.TraceMode())
 
 	// Start compactor
 	compactor := storage.NewCompactor(store)
 	compactor.Start(ctx)
-	log.Printf("Compactor started")
 
 	// Start ingestion loop
-	go runIngestion(ctx, fetcher, store, server, chainLabel, lookahead)
-	log.Printf("Ingestion started for chain %s", chainID)
+	go runIngestion(ctx, fetcher, store, server, chainLabel, cfg.Lookahead)
+	log.Printf("[%s] Ingestion started", cfg.Name)
 
-	// Start API server (auto-finds available port starting from base)
-	actualAddr, err := server.Start(serverAddr)
-	if err != nil {
-		log.Fatalf("Failed to start server: %v", err)
-	}
-	log.Printf("Server listening on %s", actualAddr)
+	return server, nil
+}
 
-	// Start metrics server
-	metrics.StartServer(consts.MetricsListenAddr)
+// newFetcher creates the chain's RPC controller and fetcher and resolves the
+// trace mode against the node; cfg.evmChainID must be set
+func newFetcher(ctx context.Context, cfg *ChainSpec, chainLabel string) (*rpc.Fetcher, error) {
+	controller := rpc.NewController(rpc.ChainConfig{
+		ChainID:        cfg.evmChainID,
+		Name:           chainLabel,
+		URL:            cfg.RPCURL,
+		FallbackURLs:   cfg.FallbackURLs,
+		MaxParallelism: cfg.MaxParallelism,
+		HedgeDelayMs:   cfg.HedgeDelayMs,
+	})
 
-	// Block forever
-	select {}
+	fetcher, err := rpc.NewFetcher(rpc.FetcherConfig{
+		Controller: controller,
+		ChainID:    cfg.evmChainID,
+		ChainName:  chainLabel,
+		TraceMode:  rpc.TraceMode(cfg.TraceMode),
+		Ctx:        ctx,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create fetcher: %w", err)
+	}
+	if _, err := fetcher.DetectTraceMode(ctx); err != nil {
+		return nil, err
+	}
+	return fetcher, nil
 }
 
 func runIngestion(ctx context.Context, fetcher *rpc.Fetcher, store storage.Storage, server *api.Server, chainLabel string, lookahead int) {
@@ -120,24 +159,38 @@ func runIngestion(ctx context.Context, fetcher *rpc.Fetcher, store storage.Stora
 		currentBlock := uint64(1)
 		if latest, ok := store.LatestBlock(); ok {
 			currentBlock = latest + 1
-			log.Printf("Resuming from individual blocks at block %d", currentBlock)
+			log.Printf("[%s] Resuming from individual blocks at block %d", chainLabel, currentBlock)
 		} else if latestBatch, ok := store.LatestBatch(); ok {
 			currentBlock = latestBatch + 1
-			log.Printf("Resuming from batches at block %d", currentBlock)
+			log.Printf("[%s] Resuming from batches at block %d", chainLabel, currentBlock)
 		} else if meta := store.GetMeta(); meta > 0 {
 			currentBlock = meta + 1
-			log.Printf("Resuming from meta at block %d", currentBlock)
+			log.Printf("[%s] Resuming from meta at block %d", chainLabel, currentBlock)
 		} else {
-			log.Printf("Starting from block 1")
+			log.Printf("[%s] Starting from block 1", chainLabel)
+		}
+
+		// Hash of the block we resume after, so the first fetched block can be linked to it
+		prevHash := ""
+		if currentBlock > 1 {
+			hash, err := storedBlockHash(store, currentBlock-1)
+			if err != nil {
+				log.Printf("[%s] Failed to read hash of block %d: %v", chainLabel, currentBlock-1, err)
+			}
+			prevHash = hash
 		}
 
 		blocksCh := make(chan *rpc.NormalizedBlock, lookahead)
+		streamErrCh := make(chan error, 1)
+		streamCtx, cancelStream := context.WithCancel(ctx)
 
 		// Start streaming
 		go func() {
-			if err := fetcher.StreamBlocks(ctx, currentBlock, lookahead, blocksCh); err != nil {
-				log.Printf("Stream error: %v", err)
+			err := fetcher.StreamBlocks(streamCtx, currentBlock, prevHash, lookahead, blocksCh)
+			if err != nil {
+				log.Printf("[%s] Stream error: %v", chainLabel, err)
 			}
+			streamErrCh <- err
 			close(blocksCh)
 		}()
 
@@ -150,28 +203,28 @@ func runIngestion(ctx context.Context, fetcher *rpc.Fetcher, store storage.Stora
 			// Extract block number from the block itself
 			blockNum, err := parseBlockNumber(block.Block.Number)
 			if err != nil {
-				log.Printf("Failed to parse block number: %v", err)
+				log.Printf("[%s] Failed to parse block number: %v", chainLabel, err)
 				break
 			}
 
 			// Verify ordering
 			if blockNum != currentBlock {
-				log.Printf("Block number mismatch: expected %d, got %d", currentBlock, blockNum)
+				log.Printf("[%s] Block number mismatch: expected %d, got %d", chainLabel, currentBlock, blockNum)
 				break
 			}
 
 			data, err := json.Marshal(block)
 			if err != nil {
-				log.Printf("Failed to marshal block %d: %v", blockNum, err)
+				log.Printf("[%s] Failed to marshal block %d: %v", chainLabel, blockNum, err)
 				break
 			}
 
 			if err := store.SaveBlock(blockNum, data); err != nil {
-				log.Printf("Failed to save block %d: %v", blockNum, err)
+				log.Printf("[%s] Failed to save block %d: %v", chainLabel, blockNum, err)
 				break
 			}
 
-			server.UpdateLatestBlock(blockNum)
+			server.PublishBlock(blockNum, data)
 			metrics.BlocksTotal.WithLabelValues(chainLabel).Inc()
 			metrics.LastIngestedBlock.WithLabelValues(chainLabel).Set(float64(blockNum))
 			currentBlock++
@@ -193,7 +246,7 @@ func runIngestion(ctx context.Context, fetcher *rpc.Fetcher, store storage.Stora
 				metrics.ChainHead.WithLabelValues(chainLabel).Set(float64(latestBlock))
 				eta := time.Duration(float64(blocksRemaining)/avgBlocksPerSec) * time.Second
 
-				log.Printf("block %d | %.1f blk/s avg | %d behind, eta %s | p=%d p95=%dms",
+				log.Printf("[%s] block %d | %.1f blk/s avg | %d behind, eta %s | p=%d p95=%dms", chainLabel,
 					currentBlock-1,
 					avgBlocksPerSec, blocksRemaining, formatDuration(eta),
 					fetcher.Controller().CurrentParallelism(),
@@ -201,8 +254,23 @@ func runIngestion(ctx context.Context, fetcher *rpc.Fetcher, store storage.Stora
 			}
 		}
 
+		// Stop the fetcher (if we broke out early) and collect its error
+		cancelStream()
+		for range blocksCh {
+		}
+		streamErr := <-streamErrCh
+
+		var reorgErr *rpc.ReorgError
+		if errors.As(streamErr, &reorgErr) {
+			if err := handleReorg(ctx, chainLabel, fetcher, store, server, reorgErr.BlockNum-1); err != nil {
+				log.Printf("[%s] Reorg handling failed: %v", chainLabel, err)
+			} else {
+				continue // Resume immediately from the fork point
+			}
+		}
+
 		// Stream ended - wait and retry
-		log.Printf("Ingestion stopped, restarting in 5s...")
+		log.Printf("[%s] Ingestion stopped, restarting in 5s...", chainLabel)
 		select {
 		case <-ctx.Done():
 			return
@@ -211,6 +279,54 @@ func runIngestion(ctx context.Context, fetcher *rpc.Fetcher, store storage.Stora
 	}
 }
 
+// handleReorg walks back from divergedAt until the stored hash matches the
+// node's canonical hash, then rolls storage and consumers back to that block
+func handleReorg(ctx context.Context, chainLabel string, fetcher *rpc.Fetcher, store storage.Storage, server *api.Server, divergedAt uint64) error {
+	forkPoint := uint64(0)
+	for depth := 0; depth < consts.FetcherMaxReorgDepth && divergedAt > 0; depth++ {
+		storedHash, err := storedBlockHash(store, divergedAt)
+		if err != nil {
+			return fmt.Errorf("read stored block %d: %w", divergedAt, err)
+		}
+		canonicalHash, err := fetcher.GetBlockHash(ctx, divergedAt)
+		if err != nil {
+			return fmt.Errorf("fetch canonical hash of block %d: %w", divergedAt, err)
+		}
+		if storedHash == canonicalHash {
+			forkPoint = divergedAt
+			break
+		}
+		divergedAt--
+	}
+	if forkPoint == 0 {
+		return fmt.Errorf("no common ancestor within %d blocks", consts.FetcherMaxReorgDepth)
+	}
+
+	log.Printf("[%s] Reorg detected, rolling back to block %d", chainLabel, forkPoint)
+	if err := storage.Rollback(store, forkPoint); err != nil {
+		return fmt.Errorf("rollback storage to %d: %w", forkPoint, err)
+	}
+	server.Rollback(forkPoint)
+	return nil
+}
+
+// storedBlockHash reads the hash of a stored block (individual or compacted)
+func storedBlockHash(store storage.Storage, blockNum uint64) (string, error) {
+	data, err := storage.LoadBlock(store, blockNum)
+	if err != nil {
+		return "", err
+	}
+	var nb struct {
+		Block struct {
+			Hash string `json:"hash"`
+		} `json:"block"`
+	}
+	if err := json.Unmarshal(data, &nb); err != nil {
+		return "", fmt.Errorf("unmarshal block %d: %w", blockNum, err)
+	}
+	return nb.Block.Hash, nil
+}
+
 // fetchChainID gets the chain ID from the RPC endpoint
 func fetchChainID(rpcURL string) (uint64, error) {
 	reqBody, _ := json.Marshal(rpc.JSONRPCRequest{
@@ -55,6 +55,70 @@ var (
 		[]string{"chain", "status"},
 	)
 
+	// EndpointHealthy is 1 when an upstream endpoint is eligible for routing
+	EndpointHealthy = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ingestion_rpc_endpoint_healthy",
+			Help: "Whether the upstream RPC endpoint is healthy (1) or not (0)",
+		},
+		[]string{"chain", "endpoint"},
+	)
+
+	// EndpointP95Seconds shows P95 latency per upstream endpoint
+	EndpointP95Seconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ingestion_rpc_endpoint_p95_seconds",
+			Help: "P95 request latency of the upstream RPC endpoint",
+		},
+		[]string{"chain", "endpoint"},
+	)
+
+	// EndpointErrorRate shows the share of failed requests per upstream endpoint
+	EndpointErrorRate = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ingestion_rpc_endpoint_error_rate",
+			Help: "Share of failed requests to the upstream RPC endpoint",
+		},
+		[]string{"chain", "endpoint"},
+	)
+
+	// EndpointHeadLag shows how many blocks an endpoint is behind the best head
+	EndpointHeadLag = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ingestion_rpc_endpoint_head_lag",
+			Help: "Blocks the upstream RPC endpoint is behind the best known head",
+		},
+		[]string{"chain", "endpoint"},
+	)
+
+	// HedgedRequestsTotal counts hedged trace requests by which copy won
+	HedgedRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ingestion_rpc_hedged_requests_total",
+			Help: "Trace requests re-sent to a second endpoint, by winner (primary/hedge)",
+		},
+		[]string{"chain", "winner"},
+	)
+
+	// TipDeliverySeconds measures time from a block being published to it being sent to a tip consumer
+	TipDeliverySeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "ingestion_ws_tip_delivery_seconds",
+			Help:    "Time from block publish to WebSocket send for consumers at the tip",
+			Buckets: prometheus.ExponentialBuckets(0.0001, 2, 16), // 100us to ~3s
+		},
+		[]string{"chain"},
+	)
+
+	// BackfillBlocksRemaining shows historical blocks not yet filled by backfill lanes
+	BackfillBlocksRemaining = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ingestion_backfill_blocks_remaining",
+			Help: "Historical blocks below the tip lane not yet stored",
+		},
+		[]string{"chain"},
+	)
+
 	// Client buffer metrics
 
 	// ClientBufferUsedBytes shows current compressed bytes in buffer
@@ -106,6 +170,13 @@ func init() {
 	prometheus.MustRegister(LastIngestedBlock)
 	prometheus.MustRegister(ChainHead)
 	prometheus.MustRegister(RPCRequestsTotal)
+	prometheus.MustRegister(EndpointHealthy)
+	prometheus.MustRegister(EndpointP95Seconds)
+	prometheus.MustRegister(EndpointErrorRate)
+	prometheus.MustRegister(EndpointHeadLag)
+	prometheus.MustRegister(HedgedRequestsTotal)
+	prometheus.MustRegister(TipDeliverySeconds)
+	prometheus.MustRegister(BackfillBlocksRemaining)
 	prometheus.MustRegister(ClientBufferUsedBytes)
 	prometheus.MustRegister(ClientBufferCapacityBytes)
 	prometheus.MustRegister(ClientBatchesProcessedTotal)
@@ -125,6 +196,7 @@ func InitChain(chainLabel string) {
 	BlocksBehind.WithLabelValues(chainLabel).Set(0)
 	LastIngestedBlock.WithLabelValues(chainLabel).Set(0)
 	ChainHead.WithLabelValues(chainLabel).Set(0)
+	BackfillBlocksRemaining.WithLabelValues(chainLabel).Set(0)
 	RPCRequestsTotal.WithLabelValues(chainLabel, "success").Add(0)
 	RPCRequestsTotal.WithLabelValues(chainLabel, "error").Add(0)
 }
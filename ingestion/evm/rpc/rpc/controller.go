@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/containerman17/l1-data-tools/ingestion/evm/rpc/consts"
+	"github.com/containerman17/l1-data-tools/ingestion/evm/rpc/metrics"
 )
 
 type RequestMetric struct {
@@ -18,6 +19,9 @@ type RequestMetric struct {
 
 type Controller struct {
 	url             string
+	chainLabel      string
+	endpoints       []*Endpoint // endpoints[0] is the primary (url)
+	hedgeDelay      time.Duration
 	maxParallelism  int
 	minParallelism  int
 	targetLatency   time.Duration
@@ -50,8 +54,18 @@ func NewController(cfg ChainConfig) *Controller {
 	// Derive everything else from maxParallelism
 	minP := max(2, maxP/10)
 
+	endpoints := []*Endpoint{newEndpoint(cfg.URL)}
+	for _, u := range cfg.FallbackURLs {
+		if u != "" && u != cfg.URL {
+			endpoints = append(endpoints, newEndpoint(u))
+		}
+	}
+
 	c := &Controller{
 		url:             cfg.URL,
+		chainLabel:      metrics.ChainLabel(cfg.Name, cfg.ChainID),
+		endpoints:       endpoints,
+		hedgeDelay:      time.Duration(cfg.HedgeDelayMs) * time.Millisecond,
 		maxParallelism:  maxP,
 		minParallelism:  minP,
 		targetLatency:   targetLatency,
@@ -72,13 +86,28 @@ func NewController(cfg ChainConfig) *Controller {
 	c.wg.Add(1)
 	go c.adjustLoop()
 
+	// Health probes only matter when there is something to fail over to
+	if len(endpoints) > 1 {
+		c.wg.Add(1)
+		go c.probeLoop()
+	}
+
 	return c
 }
 
+// URL returns the primary endpoint's URL (used for the newHeads WebSocket)
 func (c *Controller) URL() string {
 	return c.url
 }
 
+// HedgeDelay returns how long to wait before hedging a trace request (0 = disabled)
+func (c *Controller) HedgeDelay() time.Duration {
+	if len(c.endpoints) < 2 {
+		return 0
+	}
+	return c.hedgeDelay
+}
+
 func (c *Controller) CurrentParallelism() int {
 	return int(c.currentParallel.Load())
 }
@@ -0,0 +1,258 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/containerman17/l1-data-tools/ingestion/evm/rpc/consts"
+	"github.com/containerman17/l1-data-tools/ingestion/evm/rpc/metrics"
+)
+
+// Endpoint is one upstream RPC node in the controller's pool
+type Endpoint struct {
+	url   string
+	label string // host+path, safe for metrics (no query string / API keys)
+
+	head atomic.Uint64
+
+	metrics   []RequestMetric
+	metricsMu sync.Mutex
+}
+
+func newEndpoint(rawURL string) *Endpoint {
+	label := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		label = u.Host + u.Path
+	}
+	return &Endpoint{
+		url:     rawURL,
+		label:   label,
+		metrics: make([]RequestMetric, 0, 1000),
+	}
+}
+
+// URL returns the endpoint's RPC URL
+func (e *Endpoint) URL() string {
+	return e.url
+}
+
+// Record records the outcome of one HTTP request to this endpoint
+func (e *Endpoint) Record(duration time.Duration, success bool) {
+	e.metricsMu.Lock()
+	e.metrics = append(e.metrics, RequestMetric{
+		Timestamp: time.Now(),
+		Duration:  duration,
+		Success:   success,
+	})
+	e.metricsMu.Unlock()
+}
+
+// endpointStats is a snapshot used for scoring
+type endpointStats struct {
+	p95       time.Duration
+	errorRate float64
+	requests  int
+}
+
+// stats prunes the window and returns P95 latency and error rate
+func (e *Endpoint) stats() endpointStats {
+	e.metricsMu.Lock()
+	defer e.metricsMu.Unlock()
+
+	cutoff := time.Now().Add(-consts.RPCMetricsWindow)
+	validStart := len(e.metrics)
+	for i, m := range e.metrics {
+		if m.Timestamp.After(cutoff) {
+			validStart = i
+			break
+		}
+	}
+	e.metrics = e.metrics[validStart:]
+
+	if len(e.metrics) == 0 {
+		return endpointStats{}
+	}
+
+	errors := 0
+	durations := make([]time.Duration, 0, len(e.metrics))
+	for _, m := range e.metrics {
+		if !m.Success {
+			errors++
+		}
+		durations = append(durations, m.Duration)
+	}
+	sort.Slice(durations, func(i, j int) bool {
+		return durations[i] < durations[j]
+	})
+	p95Idx := int(float64(len(durations)) * 0.95)
+	if p95Idx >= len(durations) {
+		p95Idx = len(durations) - 1
+	}
+
+	return endpointStats{
+		p95:       durations[p95Idx],
+		errorRate: float64(errors) / float64(len(e.metrics)),
+		requests:  len(e.metrics),
+	}
+}
+
+// endpointScore is lower-is-better. Unhealthy endpoints sort after all healthy ones.
+type endpointScore struct {
+	healthy bool
+	cost    float64
+}
+
+func (c *Controller) score(e *Endpoint, maxHead uint64) endpointScore {
+	st := e.stats()
+
+	lag := uint64(0)
+	if head := e.head.Load(); head > 0 && maxHead > head {
+		lag = maxHead - head
+	}
+
+	healthy := lag <= consts.RPCMaxHeadLag &&
+		(st.requests < 10 || st.errorRate <= consts.RPCMaxEndpointErrorRate)
+
+	// Latency weighted by errors; endpoints without data get the target latency
+	latency := st.p95
+	if st.requests < 10 {
+		latency = c.targetLatency
+	}
+	cost := float64(latency) * (1 + 10*st.errorRate)
+
+	metrics.EndpointP95Seconds.WithLabelValues(c.chainLabel, e.label).Set(st.p95.Seconds())
+	metrics.EndpointErrorRate.WithLabelValues(c.chainLabel, e.label).Set(st.errorRate)
+	metrics.EndpointHeadLag.WithLabelValues(c.chainLabel, e.label).Set(float64(lag))
+	healthyVal := 0.0
+	if healthy {
+		healthyVal = 1
+	}
+	metrics.EndpointHealthy.WithLabelValues(c.chainLabel, e.label).Set(healthyVal)
+
+	return endpointScore{healthy: healthy, cost: cost}
+}
+
+// rankEndpoints returns endpoints ordered best-first
+func (c *Controller) rankEndpoints() []*Endpoint {
+	if len(c.endpoints) == 1 {
+		return c.endpoints
+	}
+
+	maxHead := uint64(0)
+	for _, e := range c.endpoints {
+		maxHead = max(maxHead, e.head.Load())
+	}
+
+	type ranked struct {
+		e     *Endpoint
+		score endpointScore
+	}
+	items := make([]ranked, len(c.endpoints))
+	for i, e := range c.endpoints {
+		items[i] = ranked{e: e, score: c.score(e, maxHead)}
+	}
+	// Stable keeps config order (primary first) among equal scores
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].score.healthy != items[j].score.healthy {
+			return items[i].score.healthy
+		}
+		return items[i].score.cost < items[j].score.cost
+	})
+
+	out := make([]*Endpoint, len(items))
+	for i, it := range items {
+		out[i] = it.e
+	}
+	return out
+}
+
+// PickEndpoint returns the healthiest endpoint, skipping exclude when another exists
+func (c *Controller) PickEndpoint(exclude *Endpoint) *Endpoint {
+	ranked := c.rankEndpoints()
+	for _, e := range ranked {
+		if e != exclude {
+			return e
+		}
+	}
+	return ranked[0]
+}
+
+// Endpoints returns the number of upstream endpoints in the pool
+func (c *Controller) Endpoints() int {
+	return len(c.endpoints)
+}
+
+// probeLoop polls eth_blockNumber on every endpoint to measure head lag
+func (c *Controller) probeLoop() {
+	defer c.wg.Done()
+	ticker := time.NewTicker(consts.RPCAdjustInterval)
+	defer ticker.Stop()
+
+	httpClient := &http.Client{Timeout: consts.RPCProbeTimeout}
+	for {
+		for _, e := range c.endpoints {
+			if head, err := probeBlockNumber(httpClient, e.url); err == nil {
+				e.head.Store(head)
+			} else {
+				e.Record(consts.RPCProbeTimeout, false)
+			}
+		}
+		// Refresh gauges even when no requests are routed
+		c.rankEndpoints()
+
+		select {
+		case <-c.stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func probeBlockNumber(httpClient *http.Client, rpcURL string) (uint64, error) {
+	reqData, err := json.Marshal(JSONRPCRequest{
+		Jsonrpc: "2.0",
+		Method:  "eth_blockNumber",
+		Params:  []interface{}{},
+		ID:      1,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), consts.RPCProbeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "POST", rpcURL, bytes.NewReader(reqData))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	var rpcResp JSONRPCResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return 0, err
+	}
+	if rpcResp.Error != nil {
+		return 0, fmt.Errorf("RPC error: %s", rpcResp.Error.Message)
+	}
+	var hexNum string
+	if err := json.Unmarshal(rpcResp.Result, &hexNum); err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimPrefix(hexNum, "0x"), 16, 64)
+}
@@ -28,12 +28,14 @@ type Fetcher struct {
 	chainID        uint64
 	chainName      string
 	chainLabel     string
+	traceMode      TraceMode
 }
 
 type FetcherConfig struct {
 	Controller *Controller
 	ChainID    uint64
 	ChainName  string
+	TraceMode  TraceMode       // Default: callTracer. auto needs DetectTraceMode before streaming
 	Ctx        context.Context // For HeadTracker WebSocket
 }
 
@@ -68,6 +70,11 @@ func NewFetcher(cfg FetcherConfig) (*Fetcher, error) {
 		}).DialContext,
 	}
 
+	traceMode := cfg.TraceMode
+	if traceMode == "" {
+		traceMode = TraceModeCall
+	}
+
 	return &Fetcher{
 		controller:     cfg.Controller,
 		headTracker:    headTracker,
@@ -78,6 +85,7 @@ func NewFetcher(cfg FetcherConfig) (*Fetcher, error) {
 		chainID:        cfg.ChainID,
 		chainName:      cfg.ChainName,
 		chainLabel:     metrics.ChainLabel(cfg.ChainName, cfg.ChainID),
+		traceMode:      traceMode,
 		httpClient: &http.Client{
 			Timeout:   consts.FetcherHTTPTimeout,
 			Transport: transport,
@@ -85,6 +93,11 @@ func NewFetcher(cfg FetcherConfig) (*Fetcher, error) {
 	}, nil
 }
 
+// TraceMode returns what the fetcher stores in NormalizedBlock.Traces
+func (f *Fetcher) TraceMode() TraceMode {
+	return f.traceMode
+}
+
 // Controller returns the underlying RPC controller
 func (f *Fetcher) Controller() *Controller {
 	return f.controller
@@ -98,6 +111,45 @@ type txInfo struct {
 	txIdx    int
 }
 
+// postBatch sends one JSON-RPC batch to ep and decodes the responses sorted by ID
+func (f *Fetcher) postBatch(ctx context.Context, ep *Endpoint, jsonData []byte) ([]JSONRPCResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", ep.URL(), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+
+	var responses []JSONRPCResponse
+	err = json.NewDecoder(resp.Body).Decode(&responses)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	sort.Slice(responses, func(i, j int) bool {
+		return responses[i].ID < responses[j].ID
+	})
+	return responses, nil
+}
+
+// checkBatchIDs verifies one response per request, in request order
+func checkBatchIDs(requests []JSONRPCRequest, responses []JSONRPCResponse) error {
+	if len(responses) != len(requests) {
+		return fmt.Errorf("response count mismatch: sent %d, got %d", len(requests), len(responses))
+	}
+	for i, resp := range responses {
+		if resp.ID != requests[i].ID {
+			return fmt.Errorf("response ID mismatch at index %d: expected %d, got %d", i, requests[i].ID, resp.ID)
+		}
+	}
+	return nil
+}
+
 func (f *Fetcher) batchRpcCall(ctx context.Context, requests []JSONRPCRequest) ([]JSONRPCResponse, error) {
 	if len(requests) == 0 {
 		return []JSONRPCResponse{}, nil
@@ -108,8 +160,8 @@ func (f *Fetcher) batchRpcCall(ctx context.Context, requests []JSONRPCRequest) (
 		return nil, fmt.Errorf("failed to marshal batch request: %w", err)
 	}
 
-	var responses []JSONRPCResponse
 	var lastErr error
+	var lastEp *Endpoint
 
 	for attempt := 0; attempt <= f.maxRetries; attempt++ {
 		if attempt > 0 {
@@ -122,61 +174,36 @@ func (f *Fetcher) batchRpcCall(ctx context.Context, requests []JSONRPCRequest) (
 			time.Sleep(delay)
 		}
 
-		req, err := http.NewRequestWithContext(ctx, "POST", f.controller.URL(), bytes.NewBuffer(jsonData))
-		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
-		}
-		req.Header.Set("Content-Type", "application/json")
+		// Fail over: never retry on the endpoint that just failed if another exists
+		ep := f.controller.PickEndpoint(lastEp)
+		start := time.Now()
 
-		resp, err := f.httpClient.Do(req)
-		if err != nil {
-			metrics.RPCRequestsTotal.WithLabelValues(f.chainLabel, "error").Inc()
-			lastErr = fmt.Errorf("failed to make batch request: %w", err)
-			continue
+		responses, err := f.postBatch(ctx, ep, jsonData)
+		if err == nil {
+			err = checkBatchIDs(requests, responses)
 		}
-
-		decoder := json.NewDecoder(resp.Body)
-		err = decoder.Decode(&responses)
-		resp.Body.Close()
-
 		if err != nil {
+			ep.Record(time.Since(start), false)
 			metrics.RPCRequestsTotal.WithLabelValues(f.chainLabel, "error").Inc()
-			lastErr = fmt.Errorf("failed to unmarshal batch response: %w", err)
+			lastErr = fmt.Errorf("batch request to %s: %w", ep.label, err)
+			lastEp = ep
 			continue
 		}
 
-		if len(responses) != len(requests) {
-			metrics.RPCRequestsTotal.WithLabelValues(f.chainLabel, "error").Inc()
-			lastErr = fmt.Errorf("batch response count mismatch: sent %d, got %d", len(requests), len(responses))
-			continue
-		}
-
-		sort.Slice(responses, func(i, j int) bool {
-			return responses[i].ID < responses[j].ID
-		})
-
-		validationErr := false
 		for i, resp := range responses {
-			if resp.ID != requests[i].ID {
-				lastErr = fmt.Errorf("batch response ID mismatch at index %d: expected %d, got %d", i, requests[i].ID, resp.ID)
-				validationErr = true
-				break
-			}
 			if resp.Error != nil {
+				ep.Record(time.Since(start), false)
 				metrics.RPCRequestsTotal.WithLabelValues(f.chainLabel, "error").Inc()
 				return nil, fmt.Errorf("RPC error in batch at index %d (ID %d): %s", i, resp.ID, resp.Error.Message)
 			}
 			if len(resp.Result) == 0 {
+				ep.Record(time.Since(start), false)
 				metrics.RPCRequestsTotal.WithLabelValues(f.chainLabel, "error").Inc()
 				return nil, fmt.Errorf("empty result in batch response at index %d (ID %d)", i, resp.ID)
 			}
 		}
 
-		if validationErr {
-			metrics.RPCRequestsTotal.WithLabelValues(f.chainLabel, "error").Inc()
-			continue
-		}
-
+		ep.Record(time.Since(start), true)
 		metrics.RPCRequestsTotal.WithLabelValues(f.chainLabel, "success").Inc()
 		return responses, nil
 	}
@@ -194,8 +221,8 @@ func (f *Fetcher) batchRpcCallDebug(ctx context.Context, requests []JSONRPCReque
 		return nil, fmt.Errorf("failed to marshal debug batch request: %w", err)
 	}
 
-	var responses []JSONRPCResponse
 	var lastErr error
+	var lastEp *Endpoint
 
 	for attempt := 0; attempt <= f.maxRetries; attempt++ {
 		if attempt > 0 {
@@ -208,58 +235,90 @@ func (f *Fetcher) batchRpcCallDebug(ctx context.Context, requests []JSONRPCReque
 			time.Sleep(delay)
 		}
 
-		req, err := http.NewRequestWithContext(ctx, "POST", f.controller.URL(), bytes.NewBuffer(jsonData))
-		if err != nil {
-			return nil, fmt.Errorf("failed to create debug request: %w", err)
-		}
-		req.Header.Set("Content-Type", "application/json")
-
-		resp, err := f.httpClient.Do(req)
+		ep := f.controller.PickEndpoint(lastEp)
+		responses, err := f.hedgedDebugBatch(ctx, ep, requests, jsonData)
 		if err != nil {
 			metrics.RPCRequestsTotal.WithLabelValues(f.chainLabel, "error").Inc()
-			lastErr = fmt.Errorf("failed to make debug batch request: %w", err)
+			lastErr = err
+			lastEp = ep
 			continue
 		}
 
-		decoder := json.NewDecoder(resp.Body)
-		err = decoder.Decode(&responses)
-		resp.Body.Close()
+		metrics.RPCRequestsTotal.WithLabelValues(f.chainLabel, "success").Inc()
+		return responses, nil
+	}
 
-		if err != nil {
-			metrics.RPCRequestsTotal.WithLabelValues(f.chainLabel, "error").Inc()
-			lastErr = fmt.Errorf("failed to unmarshal debug batch response: %w", err)
-			continue
-		}
+	return nil, fmt.Errorf("debug batch request failed after %d retries: %w", f.maxRetries, lastErr)
+}
 
-		sort.Slice(responses, func(i, j int) bool {
-			return responses[i].ID < responses[j].ID
-		})
+// debugAttempt is the outcome of one copy of a (possibly hedged) debug batch
+type debugAttempt struct {
+	responses []JSONRPCResponse
+	err       error
+	hedge     bool
+}
 
-		if len(responses) != len(requests) {
-			metrics.RPCRequestsTotal.WithLabelValues(f.chainLabel, "error").Inc()
-			lastErr = fmt.Errorf("debug batch response count mismatch: sent %d, got %d", len(requests), len(responses))
-			continue
+// hedgedDebugBatch sends a debug batch to ep and, if hedging is enabled and no
+// answer arrives within the hedge delay, also to the next best endpoint.
+// The first successful answer wins; the slower copy is cancelled.
+func (f *Fetcher) hedgedDebugBatch(ctx context.Context, ep *Endpoint, requests []JSONRPCRequest, jsonData []byte) ([]JSONRPCResponse, error) {
+	callCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan debugAttempt, 2)
+	send := func(target *Endpoint, hedge bool) {
+		start := time.Now()
+		responses, err := f.postBatch(callCtx, target, jsonData)
+		if err == nil {
+			err = checkBatchIDs(requests, responses)
 		}
-
-		validationErr := false
-		for i, resp := range responses {
-			if resp.ID != requests[i].ID {
-				lastErr = fmt.Errorf("debug batch response ID mismatch at index %d: expected %d, got %d", i, requests[i].ID, resp.ID)
-				validationErr = true
-				break
-			}
+		if err != nil {
+			err = fmt.Errorf("debug batch request to %s: %w", target.label, err)
 		}
-
-		if validationErr {
-			metrics.RPCRequestsTotal.WithLabelValues(f.chainLabel, "error").Inc()
-			continue
+		// A copy cancelled because the other one won says nothing about the endpoint
+		if callCtx.Err() == nil {
+			target.Record(time.Since(start), err == nil)
 		}
+		results <- debugAttempt{responses: responses, err: err, hedge: hedge}
+	}
 
-		metrics.RPCRequestsTotal.WithLabelValues(f.chainLabel, "success").Inc()
-		return responses, nil
+	go send(ep, false)
+	inFlight := 1
+
+	var hedgeTimer <-chan time.Time
+	if delay := f.controller.HedgeDelay(); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		hedgeTimer = timer.C
 	}
 
-	return nil, fmt.Errorf("debug batch request failed after %d retries: %w", f.maxRetries, lastErr)
+	var firstErr error
+	for inFlight > 0 {
+		select {
+		case <-hedgeTimer:
+			hedgeTimer = nil
+			if second := f.controller.PickEndpoint(ep); second != ep {
+				go send(second, true)
+				inFlight++
+			}
+		case res := <-results:
+			inFlight--
+			if res.err == nil {
+				if inFlight > 0 || res.hedge {
+					winner := "primary"
+					if res.hedge {
+						winner = "hedge"
+					}
+					metrics.HedgedRequestsTotal.WithLabelValues(f.chainLabel, winner).Inc()
+				}
+				return res.responses, nil
+			}
+			if firstErr == nil {
+				firstErr = res.err
+			}
+		}
+	}
+	return nil, firstErr
 }
 
 // GetLatestBlock returns the latest block number (instant, from WebSocket subscription)
@@ -282,6 +341,48 @@ func chunksOf[T any](items []T, size int) [][]T {
 	return chunks
 }
 
+// ReorgError reports a block whose parentHash does not match the previous block's hash
+type ReorgError struct {
+	BlockNum     uint64
+	ParentHash   string
+	ExpectedHash string
+}
+
+func (e *ReorgError) Error() string {
+	return fmt.Sprintf("reorg at block %d: parentHash %s, expected %s", e.BlockNum, e.ParentHash, e.ExpectedHash)
+}
+
+// GetBlockHash returns the canonical hash of a block as currently served by the RPC node
+func (f *Fetcher) GetBlockHash(ctx context.Context, blockNum uint64) (string, error) {
+	requests := []JSONRPCRequest{{
+		Jsonrpc: "2.0",
+		Method:  "eth_getBlockByNumber",
+		Params:  []interface{}{fmt.Sprintf("0x%x", blockNum), false},
+		ID:      0,
+	}}
+
+	var responses []JSONRPCResponse
+	err := f.controller.Execute(ctx, func() error {
+		var err error
+		responses, err = f.batchRpcCall(ctx, requests)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var header struct {
+		Hash string `json:"hash"`
+	}
+	if err := json.Unmarshal(responses[0].Result, &header); err != nil {
+		return "", fmt.Errorf("failed to unmarshal block header: %w", err)
+	}
+	if header.Hash == "" {
+		return "", fmt.Errorf("block %d not found", blockNum)
+	}
+	return header.Hash, nil
+}
+
 // blockResult holds the result of fetching a single block
 type blockResult struct {
 	blockNum uint64
@@ -292,7 +393,20 @@ type blockResult struct {
 // StreamBlocks fetches blocks using a sliding window approach
 // Keeps windowSize fetches in flight, processes in order as they complete
 // Runs forever until context is cancelled, polling for new blocks at tip
-func (f *Fetcher) StreamBlocks(ctx context.Context, from uint64, windowSize int, out chan<- *NormalizedBlock) error {
+// prevHash is the hash of block from-1 (empty to skip the first check); every
+// block must link to its predecessor, otherwise a *ReorgError is returned
+func (f *Fetcher) StreamBlocks(ctx context.Context, from uint64, prevHash string, windowSize int, out chan<- *NormalizedBlock) error {
+	return f.streamBlocks(ctx, from, 0, prevHash, windowSize, out)
+}
+
+// FetchRange is StreamBlocks bounded to [from, to]: it returns nil once block
+// to has been sent. Used by backfill lanes, whose ranges are below the tip.
+func (f *Fetcher) FetchRange(ctx context.Context, from, to uint64, prevHash string, windowSize int, out chan<- *NormalizedBlock) error {
+	return f.streamBlocks(ctx, from, to, prevHash, windowSize, out)
+}
+
+// streamBlocks implements StreamBlocks and FetchRange; to == 0 means unbounded
+func (f *Fetcher) streamBlocks(ctx context.Context, from, to uint64, prevHash string, windowSize int, out chan<- *NormalizedBlock) error {
 	// Map of pending fetches: blockNum -> result channel
 	pending := make(map[uint64]chan blockResult)
 	nextToSend := from
@@ -331,6 +445,9 @@ func (f *Fetcher) StreamBlocks(ctx context.Context, from uint64, windowSize int,
 		if err != nil {
 			return err
 		}
+		if to > 0 && newLatest > to {
+			newLatest = to
+		}
 		if newLatest > latestBlock {
 			latestBlock = newLatest
 		}
@@ -378,6 +495,16 @@ func (f *Fetcher) StreamBlocks(ctx context.Context, from uint64, windowSize int,
 			return fmt.Errorf("failed to fetch block %d: %w", nextToSend, result.err)
 		}
 
+		// Verify hash continuity before handing the block out
+		if prevHash != "" && result.block.Block.ParentHash != prevHash {
+			return &ReorgError{
+				BlockNum:     nextToSend,
+				ParentHash:   result.block.Block.ParentHash,
+				ExpectedHash: prevHash,
+			}
+		}
+		prevHash = result.block.Block.Hash
+
 		// Send block
 		select {
 		case out <- result.block:
@@ -386,6 +513,9 @@ func (f *Fetcher) StreamBlocks(ctx context.Context, from uint64, windowSize int,
 		}
 
 		nextToSend++
+		if to > 0 && nextToSend > to {
+			return nil
+		}
 
 		// Refill window
 		for blockNum := nextToSend + uint64(len(pending)); blockNum <= latestBlock && len(pending) < windowSize; blockNum++ {
@@ -401,6 +531,11 @@ func (f *Fetcher) StreamBlocks(ctx context.Context, from uint64, windowSize int,
 	}
 }
 
+// FetchBlock fetches one block with its receipts and traces, outside of streaming
+func (f *Fetcher) FetchBlock(ctx context.Context, blockNum uint64) (*NormalizedBlock, error) {
+	return f.fetchSingleBlock(ctx, blockNum)
+}
+
 // fetchSingleBlock fetches a single block with its receipts and traces
 func (f *Fetcher) fetchSingleBlock(ctx context.Context, blockNum uint64) (*NormalizedBlock, error) {
 	// Fetch block
@@ -437,7 +572,7 @@ func (f *Fetcher) fetchSingleBlock(ctx context.Context, blockNum uint64) (*Norma
 
 	// Fetch traces
 	var tracesMap map[string]*TraceResultOptional
-	if len(txInfos) > 0 {
+	if len(txInfos) > 0 && f.traceMode != TraceModeNone {
 		tracesMap, err = f.fetchTracesBatch(ctx, blockNum, blockNum, txInfos)
 		if err != nil {
 			return nil, fmt.Errorf("failed to fetch traces: %w", err)
@@ -448,7 +583,10 @@ func (f *Fetcher) fetchSingleBlock(ctx context.Context, blockNum uint64) (*Norma
 
 	// Assemble
 	receipts := make([]Receipt, len(block.Transactions))
-	traces := make([]TraceResultOptional, len(block.Transactions))
+	var traces []TraceResultOptional
+	if f.traceMode != TraceModeNone {
+		traces = make([]TraceResultOptional, len(block.Transactions))
+	}
 
 	for j, tx := range block.Transactions {
 		receipt, ok := receiptsMap[tx.Hash]
@@ -457,6 +595,9 @@ func (f *Fetcher) fetchSingleBlock(ctx context.Context, blockNum uint64) (*Norma
 		}
 		receipts[j] = receipt
 
+		if traces == nil {
+			continue
+		}
 		trace, ok := tracesMap[tx.Hash]
 		if ok && trace != nil {
 			traces[j] = *trace
@@ -466,9 +607,10 @@ func (f *Fetcher) fetchSingleBlock(ctx context.Context, blockNum uint64) (*Norma
 	}
 
 	return &NormalizedBlock{
-		Block:    block,
-		Receipts: receipts,
-		Traces:   traces,
+		Block:     block,
+		Receipts:  receipts,
+		Traces:    traces,
+		TraceMode: f.traceMode,
 	}, nil
 }
 
@@ -616,7 +758,7 @@ func (f *Fetcher) fetchTracesBatch(ctx context.Context, from, to uint64, txInfos
 		blockRequests = append(blockRequests, JSONRPCRequest{
 			Jsonrpc: "2.0",
 			Method:  "debug_traceBlockByNumber",
-			Params:  []interface{}{fmt.Sprintf("0x%x", blockNum), map[string]string{"tracer": "callTracer"}},
+			Params:  []interface{}{fmt.Sprintf("0x%x", blockNum), f.traceMode.TracerConfig()},
 			ID:      i,
 		})
 	}
@@ -658,8 +800,8 @@ func (f *Fetcher) fetchTracesBatch(ctx context.Context, from, to uint64, txInfos
 					return
 				}
 
-				var traces []TraceResultOptional
-				if err := StrictUnmarshal(resp.Result, &traces); err != nil {
+				traces, err := f.traceMode.parseBlockTraces(resp.Result)
+				if err != nil {
 					mu.Lock()
 					blockTraceSuccess = false
 					mu.Unlock()
@@ -698,7 +840,7 @@ func (f *Fetcher) fetchTracesBatch(ctx context.Context, from, to uint64, txInfos
 		txRequests = append(txRequests, JSONRPCRequest{
 			Jsonrpc: "2.0",
 			Method:  "debug_traceTransaction",
-			Params:  []interface{}{tx.hash, map[string]string{"tracer": "callTracer"}},
+			Params:  []interface{}{tx.hash, f.traceMode.TracerConfig()},
 			ID:      i,
 		})
 		txHashToIdx[i] = tx.hash
@@ -776,8 +918,8 @@ func (f *Fetcher) fetchTracesBatch(ctx context.Context, from, to uint64, txInfos
 					}
 				}
 
-				var trace CallTrace
-				if err := StrictUnmarshal(resp.Result, &trace); err != nil {
+				trace, err := f.traceMode.parseTxTrace(txHash, resp.Result)
+				if err != nil {
 					mu.Lock()
 					if txBatchErr == nil {
 						txBatchErr = fmt.Errorf("failed to parse trace for tx %s: %w", txHash, err)
@@ -787,7 +929,7 @@ func (f *Fetcher) fetchTracesBatch(ctx context.Context, from, to uint64, txInfos
 				}
 
 				mu.Lock()
-				tracesMap[txHash] = &TraceResultOptional{TxHash: txHash, Result: &trace}
+				tracesMap[txHash] = trace
 				mu.Unlock()
 			}
 		}(batchIdx, batch)
@@ -0,0 +1,157 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// TraceMode selects what the fetcher requests from the debug namespace for every transaction
+type TraceMode string
+
+const (
+	TraceModeCall         TraceMode = "callTracer"         // Call tree (default, what blocks stored before trace modes contain)
+	TraceModeCallWithLogs TraceMode = "callTracerWithLogs" // Call tree with the logs emitted by each frame
+	TraceModePrestateDiff TraceMode = "prestateDiff"       // prestateTracer in diff mode: balance/nonce/code/storage changes
+	TraceModeNone         TraceMode = "none"               // No traces, receipts only
+	TraceModeAuto         TraceMode = "auto"               // callTracer if the node exposes debug_*, otherwise none
+)
+
+// ParseTraceMode validates a configured trace mode. Empty means callTracer.
+func ParseTraceMode(s string) (TraceMode, error) {
+	switch mode := TraceMode(s); mode {
+	case "":
+		return TraceModeCall, nil
+	case TraceModeCall, TraceModeCallWithLogs, TraceModePrestateDiff, TraceModeNone, TraceModeAuto:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown trace mode %q (want auto, callTracer, callTracerWithLogs, prestateDiff or none)", s)
+	}
+}
+
+// Tracer returns the debug tracer name and its tracerConfig (nil when none)
+func (m TraceMode) Tracer() (string, json.RawMessage) {
+	switch m {
+	case TraceModeCallWithLogs:
+		return "callTracer", json.RawMessage(`{"withLog":true}`)
+	case TraceModePrestateDiff:
+		return "prestateTracer", json.RawMessage(`{"diffMode":true}`)
+	default:
+		return "callTracer", nil
+	}
+}
+
+// TracerConfig returns the debug_trace* options object for the mode
+func (m TraceMode) TracerConfig() map[string]interface{} {
+	name, config := m.Tracer()
+	params := map[string]interface{}{"tracer": name}
+	if config != nil {
+		params["tracerConfig"] = config
+	}
+	return params
+}
+
+// parseBlockTraces parses a debug_traceBlockByNumber result for the mode
+func (m TraceMode) parseBlockTraces(raw json.RawMessage) ([]TraceResultOptional, error) {
+	if m != TraceModePrestateDiff {
+		var traces []TraceResultOptional
+		err := StrictUnmarshal(raw, &traces)
+		return traces, err
+	}
+
+	var diffs []struct {
+		TxHash string     `json:"txHash"`
+		Result *StateDiff `json:"result"`
+	}
+	if err := StrictUnmarshal(raw, &diffs); err != nil {
+		return nil, err
+	}
+	traces := make([]TraceResultOptional, len(diffs))
+	for i, d := range diffs {
+		traces[i] = TraceResultOptional{TxHash: d.TxHash, StateDiff: d.Result}
+	}
+	return traces, nil
+}
+
+// parseTxTrace parses a debug_traceTransaction result for the mode
+func (m TraceMode) parseTxTrace(txHash string, raw json.RawMessage) (*TraceResultOptional, error) {
+	if m == TraceModePrestateDiff {
+		var diff StateDiff
+		if err := StrictUnmarshal(raw, &diff); err != nil {
+			return nil, err
+		}
+		return &TraceResultOptional{TxHash: txHash, StateDiff: &diff}, nil
+	}
+
+	var trace CallTrace
+	if err := StrictUnmarshal(raw, &trace); err != nil {
+		return nil, err
+	}
+	return &TraceResultOptional{TxHash: txHash, Result: &trace}, nil
+}
+
+// DetectTraceMode checks that the node serves debug_traceBlockByNumber for the
+// configured tracer. auto resolves to callTracer or, without debug support, to
+// none. An explicit tracer on a node without debug support is an error, so
+// ingestion fails at startup instead of retrying every block forever.
+func (f *Fetcher) DetectTraceMode(ctx context.Context) (TraceMode, error) {
+	if f.traceMode == TraceModeNone {
+		return f.traceMode, nil
+	}
+	probeMode := f.traceMode
+	if probeMode == TraceModeAuto {
+		probeMode = TraceModeCall
+	}
+
+	latest, err := f.GetLatestBlock(ctx)
+	if err != nil {
+		return "", fmt.Errorf("get latest block: %w", err)
+	}
+	jsonData, err := json.Marshal([]JSONRPCRequest{{
+		Jsonrpc: "2.0",
+		Method:  "debug_traceBlockByNumber",
+		Params:  []interface{}{fmt.Sprintf("0x%x", latest), probeMode.TracerConfig()},
+		ID:      0,
+	}})
+	if err != nil {
+		return "", err
+	}
+	responses, err := f.postBatch(ctx, f.controller.PickEndpoint(nil), jsonData)
+	if err != nil {
+		return "", fmt.Errorf("probe debug_traceBlockByNumber: %w", err)
+	}
+	if len(responses) != 1 {
+		return "", fmt.Errorf("probe debug_traceBlockByNumber: got %d responses", len(responses))
+	}
+
+	if rpcErr := responses[0].Error; rpcErr != nil {
+		if !isDebugUnavailable(rpcErr) {
+			return "", fmt.Errorf("probe debug_traceBlockByNumber: %s", rpcErr.Message)
+		}
+		if f.traceMode != TraceModeAuto {
+			return "", fmt.Errorf("trace mode %s needs debug_traceBlockByNumber, which the node does not serve (%s); use TRACE_MODE=none or auto", f.traceMode, rpcErr.Message)
+		}
+		log.Printf("[Chain %d - %s] debug_traceBlockByNumber unavailable (%s), ingesting receipts only", f.chainID, f.chainName, rpcErr.Message)
+		f.traceMode = TraceModeNone
+		return f.traceMode, nil
+	}
+
+	f.traceMode = probeMode
+	return f.traceMode, nil
+}
+
+// isDebugUnavailable reports errors meaning the debug namespace or tracer is not exposed
+func isDebugUnavailable(e *JSONRPCError) bool {
+	if e.Code == -32601 { // Method not found
+		return true
+	}
+	msg := strings.ToLower(e.Message)
+	for _, s := range []string{"does not exist", "not available", "method not found", "not supported", "disabled"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
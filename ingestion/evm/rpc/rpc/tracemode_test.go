@@ -0,0 +1,57 @@
+package rpc
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseBlockTracesPrestateDiff(t *testing.T) {
+	raw := json.RawMessage(`[{"txHash":"0xaa","result":{"pre":{"0x01":{"balance":"0x10","nonce":1}},"post":{"0x01":{"balance":"0x5","nonce":2,"storage":{"0x00":"0x01"}}}}}]`)
+
+	traces, err := TraceModePrestateDiff.parseBlockTraces(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(traces) != 1 || traces[0].Result != nil || traces[0].StateDiff == nil {
+		t.Fatalf("traces = %+v", traces)
+	}
+	post := traces[0].StateDiff.Post["0x01"]
+	if post.Balance != "0x5" || post.Nonce != 2 || post.Storage["0x00"] != "0x01" {
+		t.Fatalf("post = %+v", post)
+	}
+}
+
+func TestParseTxTraceWithLogs(t *testing.T) {
+	raw := json.RawMessage(`{"from":"0x1","gas":"0x1","gasUsed":"0x1","to":"0x2","input":"0x","type":"CALL","logs":[{"address":"0x2","topics":["0xdd"],"data":"0x","position":"0x0"}]}`)
+
+	trace, err := TraceModeCallWithLogs.parseTxTrace("0xaa", raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trace.Result == nil || len(trace.Result.Logs) != 1 || trace.Result.Logs[0].Topics[0] != "0xdd" {
+		t.Fatalf("trace = %+v", trace)
+	}
+}
+
+func TestParseTraceMode(t *testing.T) {
+	if mode, err := ParseTraceMode(""); err != nil || mode != TraceModeCall {
+		t.Fatalf("default mode = %q, %v", mode, err)
+	}
+	if _, err := ParseTraceMode("flatCallTracer"); err == nil {
+		t.Fatal("unknown mode accepted")
+	}
+}
+
+func TestIsDebugUnavailable(t *testing.T) {
+	for _, e := range []JSONRPCError{
+		{Code: -32601, Message: "the method debug_traceBlockByNumber does not exist/is not available"},
+		{Code: -32000, Message: "debug namespace is disabled"},
+	} {
+		if !isDebugUnavailable(&e) {
+			t.Errorf("%q not detected", e.Message)
+		}
+	}
+	if isDebugUnavailable(&JSONRPCError{Code: -32000, Message: "header not found"}) {
+		t.Error("unrelated error treated as missing debug support")
+	}
+}
@@ -85,11 +85,39 @@ type CallTrace struct {
 	Calls        []CallTrace `json:"calls,omitempty"`
 	Value        string      `json:"value,omitempty"`
 	Type         string      `json:"type"`
+	Logs         []CallLog   `json:"logs,omitempty"` // callTracerWithLogs only
 }
 
+// CallLog is a log emitted by a call frame (callTracer withLog)
+type CallLog struct {
+	Address  string   `json:"address"`
+	Topics   []string `json:"topics"`
+	Data     string   `json:"data"`
+	Position string   `json:"position,omitempty"` // Number of sub-calls made before this log
+	Index    string   `json:"index,omitempty"`
+}
+
+// StateDiff is prestateTracer diffMode output: touched accounts before and after the tx
+type StateDiff struct {
+	Pre  map[string]AccountState `json:"pre"`
+	Post map[string]AccountState `json:"post"`
+}
+
+// AccountState is one account in a StateDiff; unchanged fields are omitted
+type AccountState struct {
+	Balance  string            `json:"balance,omitempty"`
+	Nonce    uint64            `json:"nonce,omitempty"`
+	Code     string            `json:"code,omitempty"`
+	CodeHash string            `json:"codeHash,omitempty"`
+	Storage  map[string]string `json:"storage,omitempty"`
+}
+
+// TraceResultOptional holds one transaction's trace. Which field is set depends on
+// the block's TraceMode: Result for call tracers, StateDiff for prestateDiff.
 type TraceResultOptional struct {
-	TxHash string     `json:"txHash"`
-	Result *CallTrace `json:"result"`
+	TxHash    string     `json:"txHash"`
+	Result    *CallTrace `json:"result"`
+	StateDiff *StateDiff `json:"stateDiff,omitempty"`
 }
 
 type Receipt struct {
@@ -122,9 +150,19 @@ type Log struct {
 }
 
 type NormalizedBlock struct {
-	Block    Block                 `json:"block"`
-	Traces   []TraceResultOptional `json:"traces"`
-	Receipts []Receipt             `json:"receipts"`
+	Block     Block                 `json:"block"`
+	Traces    []TraceResultOptional `json:"traces,omitempty"`
+	Receipts  []Receipt             `json:"receipts"`
+	TraceMode TraceMode             `json:"traceMode,omitempty"` // Empty in blocks stored before trace modes: callTracer
+}
+
+// ControlFrameRollback tells consumers that blocks above ToBlock were replaced
+const ControlFrameRollback = "rollback"
+
+// ControlFrame is sent as a WebSocket text frame alongside the binary block frames
+type ControlFrame struct {
+	Type    string `json:"type"`
+	ToBlock uint64 `json:"toBlock"`
 }
 
 type JSONRPCRequest struct {
@@ -151,6 +189,8 @@ type ChainConfig struct {
 	ChainID        uint64
 	Name           string
 	URL            string
-	MaxParallelism int // Default: 200
-	MaxLatencyMs   int // Max P95 latency before reducing parallelism. Default: 1000
+	FallbackURLs   []string // Extra upstream nodes; requests go to the healthiest one
+	MaxParallelism int      // Default: 200
+	MaxLatencyMs   int      // Max P95 latency before reducing parallelism. Default: 1000
+	HedgeDelayMs   int      // Re-send slow trace requests to a second node after this delay. 0 disables
 }
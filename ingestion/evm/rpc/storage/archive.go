@@ -0,0 +1,305 @@
+package storage
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/containerman17/l1-data-tools/ingestion/evm/rpc/consts"
+)
+
+// Archive layout (one directory per chain):
+//
+//	manifest.json                       ArchiveManifest
+//	segment-{start:020d}-{end:020d}.bin one header line, then per batch:
+//	                                    uint64 start | uint32 length | zstd JSONL
+//
+// Segments hold whole compacted batches, exactly as stored, and are aligned to
+// StorageArchiveSegmentBatches so repeated exports produce the same files.
+const (
+	archiveFormat    = "evm-sink-archive/1"
+	archiveManifest  = "manifest.json"
+	segmentFileFmt   = "segment-%020d-%020d.bin"
+	segmentBlockSpan = consts.StorageArchiveSegmentBatches * BatchSize
+)
+
+// ArchiveManifest describes an exported archive directory
+type ArchiveManifest struct {
+	Format    string           `json:"format"`
+	ChainID   string           `json:"chainId"` // Avalanche blockchain ID
+	BatchSize uint64           `json:"batchSize"`
+	Segments  []ArchiveSegment `json:"segments"`
+}
+
+// ArchiveSegment is one segment file and its checksum
+type ArchiveSegment struct {
+	File   string `json:"file"`
+	Start  uint64 `json:"start"`
+	End    uint64 `json:"end"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// segmentHeader is the first line of every segment file, so files are usable on their own
+type segmentHeader struct {
+	Format    string `json:"format"`
+	ChainID   string `json:"chainId"`
+	Start     uint64 `json:"start"`
+	End       uint64 `json:"end"`
+	BatchSize uint64 `json:"batchSize"`
+}
+
+// ReadArchiveManifest loads dir/manifest.json
+func ReadArchiveManifest(dir string) (*ArchiveManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, archiveManifest))
+	if err != nil {
+		return nil, err
+	}
+	var m ArchiveManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+	if m.Format != archiveFormat {
+		return nil, fmt.Errorf("unsupported archive format %q", m.Format)
+	}
+	if m.BatchSize != BatchSize {
+		return nil, fmt.Errorf("archive batch size %d, storage uses %d", m.BatchSize, BatchSize)
+	}
+	return &m, nil
+}
+
+// ExportArchive writes compacted batches in [from, to] to segment files in dir.
+// from/to of 0 mean the first/last compacted batch; the range is narrowed to whole
+// batches. Segments already in dir's manifest for other ranges are kept.
+func ExportArchive(s Storage, dir, chainID string, from, to uint64) (*ArchiveManifest, error) {
+	firstBatch, ok := s.FirstBatch()
+	if !ok {
+		return nil, fmt.Errorf("no compacted batches to export")
+	}
+	lastBatchEnd, _ := s.LatestBatch()
+
+	if from < firstBatch {
+		from = firstBatch
+	}
+	if start := BatchStart(from); start != from {
+		from = start + BatchSize
+	}
+	if to == 0 || to > lastBatchEnd {
+		to = lastBatchEnd
+	}
+	if to != BatchEnd(BatchStart(to)) {
+		to = BatchStart(to) - 1
+	}
+	if to < from {
+		return nil, fmt.Errorf("no whole compacted batches in range")
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create archive dir: %w", err)
+	}
+
+	manifest := &ArchiveManifest{Format: archiveFormat, ChainID: chainID, BatchSize: BatchSize}
+	if existing, err := ReadArchiveManifest(dir); err == nil {
+		if existing.ChainID != chainID {
+			return nil, fmt.Errorf("archive dir holds chain %s, not %s", existing.ChainID, chainID)
+		}
+		manifest = existing
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	for segStart := from; segStart <= to; {
+		segEnd := min(((segStart-1)/segmentBlockSpan+1)*segmentBlockSpan, to)
+		seg, err := writeSegment(s, dir, chainID, segStart, segEnd)
+		if err != nil {
+			return nil, err
+		}
+		manifest.addSegment(dir, seg)
+		segStart = segEnd + 1
+	}
+
+	if err := manifest.write(dir); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// addSegment replaces segments overlapping seg and keeps the list ordered
+func (m *ArchiveManifest) addSegment(dir string, seg ArchiveSegment) {
+	kept := m.Segments[:0]
+	for _, old := range m.Segments {
+		if old.End < seg.Start || old.Start > seg.End {
+			kept = append(kept, old)
+		} else if old.File != seg.File {
+			os.Remove(filepath.Join(dir, old.File))
+		}
+	}
+	m.Segments = append(kept, seg)
+	sort.Slice(m.Segments, func(i, j int) bool { return m.Segments[i].Start < m.Segments[j].Start })
+}
+
+func (m *ArchiveManifest) write(dir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(dir, archiveManifest+".tmp")
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+	return os.Rename(tmp, filepath.Join(dir, archiveManifest))
+}
+
+// writeSegment writes batches start..end to one segment file and checksums it
+func writeSegment(s Storage, dir, chainID string, start, end uint64) (ArchiveSegment, error) {
+	seg := ArchiveSegment{File: fmt.Sprintf(segmentFileFmt, start, end), Start: start, End: end}
+	path := filepath.Join(dir, seg.File)
+
+	f, err := os.Create(path + ".tmp")
+	if err != nil {
+		return seg, fmt.Errorf("create segment: %w", err)
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	w := bufio.NewWriterSize(io.MultiWriter(f, hash), 1<<20)
+
+	header, _ := json.Marshal(segmentHeader{Format: archiveFormat, ChainID: chainID, Start: start, End: end, BatchSize: BatchSize})
+	w.Write(append(header, '\n'))
+
+	var frame [12]byte
+	for batchStart := start; batchStart <= end; batchStart += BatchSize {
+		data, err := s.GetBatchCompressed(batchStart)
+		if err != nil {
+			return seg, fmt.Errorf("read batch %d: %w", batchStart, err)
+		}
+		binary.BigEndian.PutUint64(frame[:8], batchStart)
+		binary.BigEndian.PutUint32(frame[8:], uint32(len(data)))
+		w.Write(frame[:])
+		if _, err := w.Write(data); err != nil {
+			return seg, fmt.Errorf("write segment: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return seg, fmt.Errorf("write segment: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		return seg, fmt.Errorf("sync segment: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return seg, err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return seg, fmt.Errorf("rename segment: %w", err)
+	}
+
+	seg.Size = info.Size()
+	seg.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return seg, nil
+}
+
+// ImportArchive seeds s with the archive in dir, continuing after the last
+// compacted batch already in s. The store must have no individual blocks.
+// onSegment, if set, runs after each segment (e.g. to commit a versiondb).
+// Returns the last block now covered by batches.
+func ImportArchive(s Storage, dir, chainID string, onSegment func(end uint64) error) (uint64, error) {
+	manifest, err := ReadArchiveManifest(dir)
+	if err != nil {
+		return 0, fmt.Errorf("read manifest: %w", err)
+	}
+	if chainID != "" && manifest.ChainID != chainID {
+		return 0, fmt.Errorf("archive is for chain %s, not %s", manifest.ChainID, chainID)
+	}
+	if _, ok := s.LatestBlock(); ok {
+		return 0, fmt.Errorf("storage already has individual blocks; import needs a fresh or batch-only store")
+	}
+
+	current, hasBatches := s.LatestBatch()
+	for _, seg := range manifest.Segments {
+		if seg.End <= current {
+			continue
+		}
+		if hasBatches && seg.Start > current+1 {
+			return current, fmt.Errorf("archive gap: storage ends at %d, next segment starts at %d", current, seg.Start)
+		}
+		if err := importSegment(s, dir, seg, current); err != nil {
+			return current, fmt.Errorf("import %s: %w", seg.File, err)
+		}
+		if err := s.SaveMeta(seg.End); err != nil {
+			return current, fmt.Errorf("save meta: %w", err)
+		}
+		current, hasBatches = seg.End, true
+		if onSegment != nil {
+			if err := onSegment(seg.End); err != nil {
+				return current, err
+			}
+		}
+	}
+	return current, nil
+}
+
+// importSegment checks a segment's checksum, then saves its batches above skipTo
+func importSegment(s Storage, dir string, seg ArchiveSegment, skipTo uint64) error {
+	path := filepath.Join(dir, seg.File)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(hash.Sum(nil)); got != seg.SHA256 {
+		return fmt.Errorf("checksum mismatch: got %s, manifest has %s", got, seg.SHA256)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	r := bufio.NewReaderSize(f, 1<<20)
+	headerLine, err := r.ReadBytes('\n')
+	if err != nil {
+		return fmt.Errorf("read header: %w", err)
+	}
+	var header segmentHeader
+	if err := json.Unmarshal(headerLine, &header); err != nil {
+		return fmt.Errorf("parse header: %w", err)
+	}
+	if header.Start != seg.Start || header.End != seg.End {
+		return fmt.Errorf("header range %d-%d does not match manifest %d-%d", header.Start, header.End, seg.Start, seg.End)
+	}
+
+	var frame [12]byte
+	for expected := seg.Start; expected <= seg.End; expected += BatchSize {
+		if _, err := io.ReadFull(r, frame[:]); err != nil {
+			return fmt.Errorf("read batch %d: %w", expected, err)
+		}
+		batchStart := binary.BigEndian.Uint64(frame[:8])
+		if batchStart != expected {
+			return fmt.Errorf("batch %d found where %d expected", batchStart, expected)
+		}
+		data := make([]byte, binary.BigEndian.Uint32(frame[8:]))
+		if _, err := io.ReadFull(r, data); err != nil {
+			return fmt.Errorf("read batch %d: %w", batchStart, err)
+		}
+		if batchStart <= skipTo {
+			continue
+		}
+		if err := s.SaveBatch(batchStart, BatchEnd(batchStart), data); err != nil {
+			return fmt.Errorf("save batch %d: %w", batchStart, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	src := seedStore(t, 300, 350)
+	dir := t.TempDir()
+
+	manifest, err := ExportArchive(src, dir, "chainA", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Segments) != 1 || manifest.Segments[0].Start != 1 || manifest.Segments[0].End != 300 {
+		t.Fatalf("segments = %+v", manifest.Segments)
+	}
+
+	dst, err := NewPebbleStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dst.Close()
+
+	if _, err := ImportArchive(dst, dir, "chainB", nil); err == nil {
+		t.Fatal("import accepted an archive for another chain")
+	}
+
+	var committed []uint64
+	last, err := ImportArchive(dst, dir, "chainA", func(end uint64) error {
+		committed = append(committed, end)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last != 300 || dst.GetMeta() != 300 || len(committed) != 1 {
+		t.Fatalf("last = %d, meta = %d, committed = %v", last, dst.GetMeta(), committed)
+	}
+	for start := uint64(1); start <= 300; start += BatchSize {
+		want, _ := src.GetBatchCompressed(start)
+		got, err := dst.GetBatchCompressed(start)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Fatalf("batch %d differs after import", start)
+		}
+	}
+}
+
+func TestArchiveChecksum(t *testing.T) {
+	src := seedStore(t, 200, 200)
+	dir := t.TempDir()
+	manifest, err := ExportArchive(src, dir, "chainA", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, manifest.Segments[0].File)
+	data, _ := os.ReadFile(path)
+	data[len(data)-1] ^= 0xff
+	os.WriteFile(path, data, 0644)
+
+	dst, err := NewPebbleStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dst.Close()
+	if _, err := ImportArchive(dst, dir, "chainA", nil); err == nil {
+		t.Fatal("import accepted a corrupted segment")
+	}
+	if _, ok := dst.LatestBatch(); ok {
+		t.Fatal("corrupted segment was partially imported")
+	}
+}
@@ -161,10 +161,12 @@ func (c *Compactor) compactOneBatch() bool {
 		return false
 	}
 
-	// Update meta
-	if err := c.store.SaveMeta(batchEnd); err != nil {
-		c.logger.Error("meta update failed", "error", err)
-		return false
+	// Update meta (backfill lanes may already have saved batches above this one)
+	if batchEnd > c.store.GetMeta() {
+		if err := c.store.SaveMeta(batchEnd); err != nil {
+			c.logger.Error("meta update failed", "error", err)
+			return false
+		}
 	}
 
 	// Delete individual blocks
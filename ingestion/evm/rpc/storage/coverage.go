@@ -0,0 +1,223 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// BlockRange is an inclusive range of block numbers
+type BlockRange struct {
+	Start uint64 `json:"start"`
+	End   uint64 `json:"end"`
+}
+
+// Coverage tracks which block ranges are stored, so ingestion can follow the
+// tip while backfill lanes fill history out of order.
+//
+// The persisted copy is written lazily and may lag behind the blocks on disk,
+// never lead them: LoadCoverage extends every range past its saved end by
+// probing storage, and Truncate is persisted before blocks are deleted.
+type Coverage struct {
+	store  Storage
+	mu     sync.RWMutex
+	ranges []BlockRange // Sorted, non-overlapping and non-adjacent
+	dirty  bool
+}
+
+// LoadCoverage reads the persisted ranges, or derives them from the batch and
+// block keys of a store written before coverage was tracked (which was always
+// contiguous), and extends each range over blocks saved after the last flush.
+func LoadCoverage(s Storage) (*Coverage, error) {
+	c := &Coverage{store: s}
+
+	ranges, ok, err := s.GetRanges()
+	if err != nil {
+		return nil, fmt.Errorf("read coverage: %w", err)
+	}
+	if ok {
+		c.ranges = ranges
+	} else if r, ok := legacyRange(s); ok {
+		c.ranges = []BlockRange{r}
+		c.dirty = true
+	}
+
+	for i := range c.ranges {
+		end := probeEnd(s, c.ranges[i].End)
+		if end != c.ranges[i].End {
+			c.ranges[i].End = end
+			c.dirty = true
+		}
+	}
+	c.normalize()
+	return c, nil
+}
+
+// legacyRange is the contiguous range of a store without persisted coverage
+func legacyRange(s Storage) (BlockRange, bool) {
+	first, hasBatch := s.FirstBatch()
+	last, _ := s.LatestBatch()
+	if !hasBatch {
+		var ok bool
+		if first, ok = s.FirstBlock(); !ok {
+			return BlockRange{}, false
+		}
+		last = first
+	}
+	if latest, ok := s.LatestBlock(); ok && latest > last {
+		last = latest
+	}
+	return BlockRange{Start: first, End: last}, true
+}
+
+// probeEnd walks forward from end over stored blocks and batches
+func probeEnd(s Storage, end uint64) uint64 {
+	for {
+		next := end + 1
+		if data, err := s.GetBlock(next); err == nil && len(data) > 0 {
+			end = next
+			continue
+		}
+		// The batch containing next, which may have been compacted after the flush
+		if data, err := s.GetBatchCompressed(BatchStart(next)); err == nil && len(data) > 0 {
+			end = BatchEnd(BatchStart(next))
+			continue
+		}
+		return end
+	}
+}
+
+// normalize sorts ranges and merges overlapping or adjacent ones. Callers hold mu.
+func (c *Coverage) normalize() {
+	sort.Slice(c.ranges, func(i, j int) bool { return c.ranges[i].Start < c.ranges[j].Start })
+	merged := c.ranges[:0]
+	for _, r := range c.ranges {
+		if n := len(merged); n > 0 && r.Start <= merged[n-1].End+1 {
+			if r.End > merged[n-1].End {
+				merged[n-1].End = r.End
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	c.ranges = merged
+}
+
+// Add marks blocks [start, end] as stored. They must already be written.
+func (c *Coverage) Add(start, end uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ranges = append(c.ranges, BlockRange{Start: start, End: end})
+	c.normalize()
+	c.dirty = true
+}
+
+// Truncate removes every block above toBlock and persists the result.
+// Call it before deleting the blocks so the saved copy never leads storage.
+func (c *Coverage) Truncate(toBlock uint64) error {
+	c.mu.Lock()
+	kept := c.ranges[:0]
+	for _, r := range c.ranges {
+		if r.Start > toBlock {
+			continue
+		}
+		if r.End > toBlock {
+			r.End = toBlock
+		}
+		kept = append(kept, r)
+	}
+	c.ranges = kept
+	c.dirty = true
+	c.mu.Unlock()
+	return c.Flush()
+}
+
+// Ranges returns a copy of the stored ranges in ascending order
+func (c *Coverage) Ranges() []BlockRange {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]BlockRange(nil), c.ranges...)
+}
+
+// Contains reports whether blockNum is stored
+func (c *Coverage) Contains(blockNum uint64) bool {
+	_, ok := c.rangeOf(blockNum)
+	return ok
+}
+
+// ContiguousEnd returns the last block of the stored run containing blockNum
+func (c *Coverage) ContiguousEnd(blockNum uint64) (uint64, bool) {
+	r, ok := c.rangeOf(blockNum)
+	return r.End, ok
+}
+
+func (c *Coverage) rangeOf(blockNum uint64) (BlockRange, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	i := sort.Search(len(c.ranges), func(i int) bool { return c.ranges[i].End >= blockNum })
+	if i < len(c.ranges) && c.ranges[i].Start <= blockNum {
+		return c.ranges[i], true
+	}
+	return BlockRange{}, false
+}
+
+// Gaps returns the missing ranges in [from, to]
+func (c *Coverage) Gaps(from, to uint64) []BlockRange {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var gaps []BlockRange
+	cur := from
+	for _, r := range c.ranges {
+		if cur > to {
+			break
+		}
+		if r.End < cur {
+			continue
+		}
+		if r.Start > cur {
+			gaps = append(gaps, BlockRange{Start: cur, End: min(r.Start-1, to)})
+		}
+		cur = r.End + 1
+	}
+	if cur <= to {
+		gaps = append(gaps, BlockRange{Start: cur, End: to})
+	}
+	return gaps
+}
+
+// Flush persists the ranges if they changed since the last flush
+func (c *Coverage) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+	if err := c.store.SaveRanges(c.ranges); err != nil {
+		return fmt.Errorf("save coverage: %w", err)
+	}
+	c.dirty = false
+	return nil
+}
+
+// FlushLoop flushes every interval until ctx is done, then once more
+func (c *Coverage) FlushLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := c.Flush(); err != nil {
+				log.Printf("[Coverage] %v", err)
+			}
+			return
+		case <-ticker.C:
+			if err := c.Flush(); err != nil {
+				log.Printf("[Coverage] %v", err)
+			}
+		}
+	}
+}
@@ -13,6 +13,7 @@ type Storage interface {
 	// Batch operations (compactor)
 	SaveBatch(start, end uint64, data []byte) error
 	GetBatchCompressed(start uint64) ([]byte, error)
+	DeleteBatch(start uint64) error
 	FirstBatch() (uint64, bool)
 	LatestBatch() (uint64, bool)
 
@@ -20,6 +21,10 @@ type Storage interface {
 	GetMeta() uint64
 	SaveMeta(lastCompacted uint64) error
 
+	// Coverage operations (see Coverage)
+	GetRanges() ([]BlockRange, bool, error)
+	SaveRanges(ranges []BlockRange) error
+
 	// Stats
 	BlockCount() int
 
@@ -2,6 +2,8 @@ package storage
 
 import (
 	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -17,6 +19,7 @@ const (
 	blockKeyFormat = "block:%020d"       // block:{blockNum}
 	batchKeyFormat = "batch:%020d-%020d" // batch:{start}-{end}
 	metaKey        = "meta"
+	rangesKey      = "ranges"
 )
 
 // PebbleStorage implements Storage using a standalone pebble database
@@ -155,6 +158,11 @@ func (s *PebbleStorage) GetBatchCompressed(start uint64) ([]byte, error) {
 	return result, nil
 }
 
+// DeleteBatch removes the compressed batch starting at start
+func (s *PebbleStorage) DeleteBatch(start uint64) error {
+	return s.db.Delete(batchKey(start, start+BatchSize-1), pebble.Sync)
+}
+
 // FirstBatch returns the start block of the first compressed batch
 func (s *PebbleStorage) FirstBatch() (uint64, bool) {
 	iter, err := s.db.NewIter(&pebble.IterOptions{
@@ -213,6 +221,32 @@ func (s *PebbleStorage) SaveMeta(lastCompacted uint64) error {
 	return s.db.Set([]byte(metaKey), data, pebble.Sync)
 }
 
+// GetRanges returns the persisted coverage ranges (false if never saved)
+func (s *PebbleStorage) GetRanges() ([]BlockRange, bool, error) {
+	data, closer, err := s.db.Get([]byte(rangesKey))
+	if errors.Is(err, pebble.ErrNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	defer closer.Close()
+	var ranges []BlockRange
+	if err := json.Unmarshal(data, &ranges); err != nil {
+		return nil, false, err
+	}
+	return ranges, true, nil
+}
+
+// SaveRanges persists the coverage ranges
+func (s *PebbleStorage) SaveRanges(ranges []BlockRange) error {
+	data, err := json.Marshal(ranges)
+	if err != nil {
+		return err
+	}
+	return s.db.Set([]byte(rangesKey), data, pebble.Sync)
+}
+
 // BlockCount returns approximate count of individual blocks stored
 func (s *PebbleStorage) BlockCount() int {
 	first, hasFirst := s.FirstBlock()
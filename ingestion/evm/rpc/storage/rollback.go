@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"fmt"
+)
+
+// LoadBlock returns a block's JSON from individual block storage,
+// falling back to decompressing the batch that contains it
+func LoadBlock(s Storage, blockNum uint64) ([]byte, error) {
+	data, err := s.GetBlock(blockNum)
+	if err == nil && len(data) > 0 {
+		return data, nil
+	}
+
+	batchStart := BatchStart(blockNum)
+	compressed, err := s.GetBatchCompressed(batchStart)
+	if err != nil {
+		return nil, fmt.Errorf("block %d not found: %w", blockNum, err)
+	}
+	blocks, err := DecompressBlocks(compressed)
+	if err != nil {
+		return nil, fmt.Errorf("decompress batch %d: %w", batchStart, err)
+	}
+	idx := blockNum - batchStart
+	if idx >= uint64(len(blocks)) {
+		return nil, fmt.Errorf("batch %d has %d blocks, block %d missing", batchStart, len(blocks), blockNum)
+	}
+	return blocks[idx], nil
+}
+
+// Rollback removes every stored block above toBlock.
+// A compacted batch straddling toBlock is un-compacted: blocks up to toBlock
+// are written back as individual blocks and the batch is deleted.
+func Rollback(s Storage, toBlock uint64) error {
+	for {
+		lastBatchEnd, ok := s.LatestBatch()
+		if !ok || lastBatchEnd <= toBlock {
+			break
+		}
+
+		batchStart := BatchStart(lastBatchEnd)
+		if batchStart <= toBlock {
+			compressed, err := s.GetBatchCompressed(batchStart)
+			if err != nil {
+				return fmt.Errorf("read batch %d: %w", batchStart, err)
+			}
+			blocks, err := DecompressBlocks(compressed)
+			if err != nil {
+				return fmt.Errorf("decompress batch %d: %w", batchStart, err)
+			}
+			for i := uint64(0); batchStart+i <= toBlock && i < uint64(len(blocks)); i++ {
+				if err := s.SaveBlock(batchStart+i, blocks[i]); err != nil {
+					return fmt.Errorf("restore block %d: %w", batchStart+i, err)
+				}
+			}
+		}
+
+		if err := s.DeleteBatch(batchStart); err != nil {
+			return fmt.Errorf("delete batch %d: %w", batchStart, err)
+		}
+		if err := s.SaveMeta(batchStart - 1); err != nil {
+			return fmt.Errorf("update meta: %w", err)
+		}
+	}
+
+	if latest, ok := s.LatestBlock(); ok && latest > toBlock {
+		if err := s.DeleteBlockRange(toBlock+1, latest); err != nil {
+			return fmt.Errorf("delete blocks %d-%d: %w", toBlock+1, latest, err)
+		}
+	}
+
+	return nil
+}
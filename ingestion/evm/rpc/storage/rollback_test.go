@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"fmt"
+	"testing"
+)
+
+func blockJSON(n uint64) []byte {
+	return []byte(fmt.Sprintf(`{"block":{"number":"0x%x"}}`, n))
+}
+
+// seedStore writes batches covering 1..compactedTo and individual blocks up to latest
+func seedStore(t *testing.T, compactedTo, latest uint64) *PebbleStorage {
+	t.Helper()
+	s, err := NewPebbleStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	for start := uint64(1); start < compactedTo; start += BatchSize {
+		var blocks [][]byte
+		for n := start; n <= BatchEnd(start); n++ {
+			blocks = append(blocks, blockJSON(n))
+		}
+		compressed, err := CompressBlocks(blocks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SaveBatch(start, BatchEnd(start), compressed); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.SaveMeta(compactedTo); err != nil {
+		t.Fatal(err)
+	}
+	for n := compactedTo + 1; n <= latest; n++ {
+		if err := s.SaveBlock(n, blockJSON(n)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+func TestRollbackIndividualBlocks(t *testing.T) {
+	s := seedStore(t, 200, 250)
+
+	if err := Rollback(s, 230); err != nil {
+		t.Fatal(err)
+	}
+
+	if latest, ok := s.LatestBlock(); !ok || latest != 230 {
+		t.Fatalf("latest block = %d, %v; want 230", latest, ok)
+	}
+	if end, _ := s.LatestBatch(); end != 200 {
+		t.Fatalf("latest batch end = %d; want 200", end)
+	}
+}
+
+func TestRollbackUncompactsBatch(t *testing.T) {
+	s := seedStore(t, 200, 250)
+
+	if err := Rollback(s, 150); err != nil {
+		t.Fatal(err)
+	}
+
+	if end, _ := s.LatestBatch(); end != 100 {
+		t.Fatalf("latest batch end = %d; want 100", end)
+	}
+	if meta := s.GetMeta(); meta != 100 {
+		t.Fatalf("meta = %d; want 100", meta)
+	}
+	if first, _ := s.FirstBlock(); first != 101 {
+		t.Fatalf("first block = %d; want 101", first)
+	}
+	if latest, _ := s.LatestBlock(); latest != 150 {
+		t.Fatalf("latest block = %d; want 150", latest)
+	}
+
+	for _, n := range []uint64{50, 101, 150} {
+		data, err := LoadBlock(s, n)
+		if err != nil {
+			t.Fatalf("load block %d: %v", n, err)
+		}
+		if string(data) != string(blockJSON(n)) {
+			t.Fatalf("block %d = %s", n, data)
+		}
+	}
+	if _, err := LoadBlock(s, 151); err == nil {
+		t.Fatal("block 151 should be gone")
+	}
+}
@@ -0,0 +1,199 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/pebble/v2"
+)
+
+// Issue kinds reported by Verify
+const (
+	IssueGap        = "gap"         // Blocks missing from both batches and individual blocks
+	IssueOverlap    = "overlap"     // Individual blocks left inside a compacted batch's range
+	IssueBadBatch   = "bad-batch"   // Misaligned key, undecodable data or wrong block count
+	IssueBadBlock   = "bad-block"   // Unparsable JSON, wrong number or receipt/trace count mismatch
+	IssueParentHash = "parent-hash" // parentHash does not match the previous block's hash
+	IssueMeta       = "meta"        // Meta key disagrees with the last compacted batch
+)
+
+// Issue is one inconsistent block range, inclusive
+type Issue struct {
+	Kind   string
+	Start  uint64
+	End    uint64
+	Detail string
+}
+
+func (i Issue) String() string {
+	if i.Start == i.End {
+		return fmt.Sprintf("%s at block %d: %s", i.Kind, i.Start, i.Detail)
+	}
+	return fmt.Sprintf("%s at blocks %d-%d: %s", i.Kind, i.Start, i.End, i.Detail)
+}
+
+// VerifyReport summarises a full storage walk
+type VerifyReport struct {
+	Batches    int
+	Blocks     int // Individual (uncompacted) blocks
+	FirstBlock uint64
+	LastBlock  uint64
+	Issues     []Issue
+}
+
+// blockSummary is the subset of a stored block Verify checks
+type blockSummary struct {
+	Block struct {
+		Number       string            `json:"number"`
+		Hash         string            `json:"hash"`
+		ParentHash   string            `json:"parentHash"`
+		Transactions []json.RawMessage `json:"transactions"`
+	} `json:"block"`
+	Receipts  []json.RawMessage `json:"receipts"`
+	Traces    []json.RawMessage `json:"traces"`
+	TraceMode string            `json:"traceMode"`
+}
+
+// verifier carries linkage state across batches and individual blocks
+type verifier struct {
+	report   *VerifyReport
+	prevNum  uint64
+	prevHash string
+}
+
+// addIssue records an issue, merging it into the previous one when contiguous
+func (v *verifier) addIssue(kind string, start, end uint64, detail string) {
+	issues := v.report.Issues
+	if n := len(issues); n > 0 {
+		last := &issues[n-1]
+		if last.Kind == kind && last.Detail == detail && last.End+1 == start {
+			last.End = end
+			return
+		}
+	}
+	v.report.Issues = append(issues, Issue{Kind: kind, Start: start, End: end, Detail: detail})
+}
+
+// expect reports a gap if the walk skipped blocks before blockNum
+func (v *verifier) expect(blockNum uint64) {
+	if v.prevNum > 0 && blockNum > v.prevNum+1 {
+		v.addIssue(IssueGap, v.prevNum+1, blockNum-1, "missing")
+		v.prevHash = ""
+	}
+}
+
+// checkBlock validates one block's JSON and its link to the previous block
+func (v *verifier) checkBlock(expected uint64, data []byte) {
+	var b blockSummary
+	if err := json.Unmarshal(data, &b); err != nil {
+		v.addIssue(IssueBadBlock, expected, expected, "invalid JSON")
+		v.prevNum, v.prevHash = expected, ""
+		return
+	}
+
+	num, err := strconv.ParseUint(strings.TrimPrefix(b.Block.Number, "0x"), 16, 64)
+	switch {
+	case err != nil || num != expected:
+		v.addIssue(IssueBadBlock, expected, expected, fmt.Sprintf("stored block number %q", b.Block.Number))
+	case len(b.Receipts) != len(b.Block.Transactions):
+		v.addIssue(IssueBadBlock, expected, expected, fmt.Sprintf("%d receipts for %d transactions", len(b.Receipts), len(b.Block.Transactions)))
+	case b.TraceMode != "none" && len(b.Traces) != len(b.Block.Transactions):
+		v.addIssue(IssueBadBlock, expected, expected, fmt.Sprintf("%d traces for %d transactions", len(b.Traces), len(b.Block.Transactions)))
+	}
+
+	if v.prevHash != "" && v.prevNum+1 == expected && !strings.EqualFold(b.Block.ParentHash, v.prevHash) {
+		v.addIssue(IssueParentHash, expected, expected, "parentHash does not match previous block")
+	}
+
+	v.prevNum, v.prevHash = expected, b.Block.Hash
+	if v.report.FirstBlock == 0 {
+		v.report.FirstBlock = expected
+	}
+	v.report.LastBlock = expected
+}
+
+// Verify walks every batch and block key in order and checks that the store is
+// contiguous, decodable and hash-linked. It reads the whole database; run it
+// against a stopped sink.
+func (s *PebbleStorage) Verify() (*VerifyReport, error) {
+	v := &verifier{report: &VerifyReport{}}
+
+	// 1. Compacted batches
+	iter, err := s.db.NewIter(&pebble.IterOptions{
+		LowerBound: []byte("batch:"),
+		UpperBound: []byte("batch;"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("open batch iterator: %w", err)
+	}
+	lastBatchEnd := uint64(0)
+	for valid := iter.First(); valid; valid = iter.Next() {
+		start, end, ok := parseBatchKey(iter.Key())
+		if !ok {
+			v.addIssue(IssueBadBatch, 0, 0, fmt.Sprintf("unparsable key %q", iter.Key()))
+			continue
+		}
+		v.report.Batches++
+		if start != BatchStart(start) || end != BatchEnd(start) {
+			v.addIssue(IssueBadBatch, start, end, "misaligned batch key")
+			continue
+		}
+		if start <= lastBatchEnd {
+			v.addIssue(IssueBadBatch, start, end, "overlaps previous batch")
+			continue
+		}
+		v.expect(start)
+		lastBatchEnd = end
+
+		blocks, err := DecompressBlocks(iter.Value())
+		if err != nil {
+			v.addIssue(IssueBadBatch, start, end, "decompress failed")
+			v.prevNum, v.prevHash = end, ""
+			continue
+		}
+		if len(blocks) != BatchSize {
+			v.addIssue(IssueBadBatch, start, end, fmt.Sprintf("%d blocks in batch", len(blocks)))
+			v.prevNum, v.prevHash = end, ""
+			continue
+		}
+		for i, block := range blocks {
+			v.checkBlock(start+uint64(i), block)
+		}
+	}
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("iterate batches: %w", err)
+	}
+
+	if meta := s.GetMeta(); lastBatchEnd > 0 && meta != lastBatchEnd {
+		v.addIssue(IssueMeta, lastBatchEnd, lastBatchEnd, fmt.Sprintf("meta is %d", meta))
+	}
+
+	// 2. Individual blocks above the batches
+	iter, err = s.db.NewIter(&pebble.IterOptions{
+		LowerBound: []byte("block:"),
+		UpperBound: []byte("block;"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("open block iterator: %w", err)
+	}
+	for valid := iter.First(); valid; valid = iter.Next() {
+		blockNum, ok := parseBlockKey(iter.Key())
+		if !ok {
+			continue
+		}
+		v.report.Blocks++
+		if blockNum <= lastBatchEnd {
+			v.addIssue(IssueOverlap, blockNum, blockNum, "also stored in a batch")
+			continue
+		}
+		v.expect(blockNum)
+		v.checkBlock(blockNum, iter.Value())
+	}
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("iterate blocks: %w", err)
+	}
+
+	return v.report, nil
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"fmt"
+	"testing"
+)
+
+func linkedBlockJSON(n uint64) []byte {
+	return []byte(fmt.Sprintf(`{"block":{"number":"0x%x","hash":"0x%x","parentHash":"0x%x","transactions":[]},"receipts":[],"traces":[]}`, n, n, n-1))
+}
+
+func TestVerify(t *testing.T) {
+	s, err := NewPebbleStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	var blocks [][]byte
+	for n := uint64(1); n <= 100; n++ {
+		blocks = append(blocks, linkedBlockJSON(n))
+	}
+	compressed, _ := CompressBlocks(blocks)
+	if err := s.SaveBatch(1, 100, compressed); err != nil {
+		t.Fatal(err)
+	}
+	s.SaveMeta(100)
+	for n := uint64(101); n <= 120; n++ {
+		s.SaveBlock(n, linkedBlockJSON(n))
+	}
+
+	report, err := s.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Issues) != 0 || report.FirstBlock != 1 || report.LastBlock != 120 {
+		t.Fatalf("clean store: %+v", report)
+	}
+
+	// Crash between SaveBatch and DeleteBlockRange, a lost block and a forked block
+	s.SaveBlock(99, linkedBlockJSON(99))
+	s.DeleteBlockRange(105, 106)
+	s.SaveBlock(110, []byte(`{"block":{"number":"0x6e","hash":"0xfork","parentHash":"0xbad","transactions":[]},"receipts":[],"traces":[]}`))
+
+	report, err = s.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Issue{
+		{Kind: IssueOverlap, Start: 99, End: 99},
+		{Kind: IssueGap, Start: 105, End: 106},
+		{Kind: IssueParentHash, Start: 110, End: 111}, // Both links around the forked block
+	}
+	if len(report.Issues) != len(want) {
+		t.Fatalf("issues = %v", report.Issues)
+	}
+	for i, w := range want {
+		got := report.Issues[i]
+		if got.Kind != w.Kind || got.Start != w.Start || got.End != w.End {
+			t.Fatalf("issue %d = %v, want %s %d-%d", i, got, w.Kind, w.Start, w.End)
+		}
+	}
+}
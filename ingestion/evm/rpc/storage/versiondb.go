@@ -2,6 +2,8 @@ package storage
 
 import (
 	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"sync/atomic"
 
 	"github.com/ava-labs/avalanchego/database"
@@ -84,7 +86,14 @@ func (s *VersionDBStorage) DeleteBlockRange(start, end uint64) error {
 			return err
 		}
 	}
-	return batch.Write()
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	// Deleting the top of the range (rollback) invalidates the cached latest
+	if end >= s.latestBlock.Load() {
+		s.initLatestBlockCache()
+	}
+	return nil
 }
 
 func (s *VersionDBStorage) SaveBatch(start, end uint64, data []byte) error {
@@ -96,6 +105,10 @@ func (s *VersionDBStorage) GetBatchCompressed(start uint64) ([]byte, error) {
 	return s.db.Get(batchKey(start, end))
 }
 
+func (s *VersionDBStorage) DeleteBatch(start uint64) error {
+	return s.db.Delete(batchKey(start, start+BatchSize-1))
+}
+
 func (s *VersionDBStorage) FirstBatch() (uint64, bool) {
 	iter := s.db.NewIteratorWithPrefix([]byte("batch:"))
 	defer iter.Release()
@@ -143,6 +156,29 @@ func (s *VersionDBStorage) SaveMeta(lastCompacted uint64) error {
 	return s.db.Put([]byte(metaKey), data)
 }
 
+func (s *VersionDBStorage) GetRanges() ([]BlockRange, bool, error) {
+	data, err := s.db.Get([]byte(rangesKey))
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	var ranges []BlockRange
+	if err := json.Unmarshal(data, &ranges); err != nil {
+		return nil, false, err
+	}
+	return ranges, true, nil
+}
+
+func (s *VersionDBStorage) SaveRanges(ranges []BlockRange) error {
+	data, err := json.Marshal(ranges)
+	if err != nil {
+		return err
+	}
+	return s.db.Put([]byte(rangesKey), data)
+}
+
 func (s *VersionDBStorage) BlockCount() int {
 	first, hasFirst := s.FirstBlock()
 	if !hasFirst {
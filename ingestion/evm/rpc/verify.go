@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/containerman17/l1-data-tools/ingestion/evm/rpc/rpc"
+	"github.com/containerman17/l1-data-tools/ingestion/evm/rpc/storage"
+)
+
+// runVerify implements `sink verify [-chain id] [-repair]`.
+// The sink must be stopped: Pebble allows a single process per database.
+func runVerify(args []string) int {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	chainFilter := fs.String("chain", "", "Only verify this chain (blockchainId or name)")
+	repair := fs.Bool("repair", false, "Re-fetch bad ranges from RPC and rewrite them")
+	fs.Parse(args)
+
+	chains, err := selectChains(*chainFilter)
+	if err != nil {
+		log.Print(err)
+		return 2
+	}
+
+	exitCode := 0
+	for _, cfg := range chains {
+		ok, err := verifyChain(cfg, *repair)
+		if err != nil {
+			log.Printf("[%s] Verify failed: %v", cfg.Name, err)
+			return 2
+		}
+		if !ok {
+			exitCode = 1
+		}
+	}
+	return exitCode
+}
+
+// verifyChain verifies one chain's store, optionally repairing it.
+// Returns true when the store is (or was repaired to be) consistent.
+func verifyChain(cfg *ChainSpec, repair bool) (bool, error) {
+	store, err := storage.NewPebbleStorage(cfg.PebblePath)
+	if err != nil {
+		return false, fmt.Errorf("open storage: %w", err)
+	}
+	defer store.Close()
+
+	report, err := store.Verify()
+	if err != nil {
+		return false, err
+	}
+	printReport(cfg.Name, report)
+	if len(report.Issues) == 0 || !repair {
+		return len(report.Issues) == 0, nil
+	}
+
+	evmChainID, err := fetchChainID(cfg.RPCURL)
+	if err != nil {
+		return false, fmt.Errorf("fetch chainID from RPC: %w", err)
+	}
+	cfg.evmChainID = evmChainID
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fetcher, err := newFetcher(ctx, cfg, fmt.Sprintf("chain-%d", evmChainID))
+	if err != nil {
+		return false, err
+	}
+	defer fetcher.Controller().Stop()
+
+	if err := repairStore(ctx, cfg.Name, fetcher, store, report.Issues); err != nil {
+		return false, err
+	}
+
+	report, err = store.Verify()
+	if err != nil {
+		return false, err
+	}
+	log.Printf("[%s] After repair:", cfg.Name)
+	printReport(cfg.Name, report)
+	return len(report.Issues) == 0, nil
+}
+
+func printReport(name string, r *storage.VerifyReport) {
+	log.Printf("[%s] %d batches, %d individual blocks, blocks %d-%d, %d issues",
+		name, r.Batches, r.Blocks, r.FirstBlock, r.LastBlock, len(r.Issues))
+	for _, issue := range r.Issues {
+		log.Printf("[%s]   %s", name, issue)
+	}
+}
+
+// repairStore fixes issues in place. Blocks inside the compacted range are
+// rewritten a whole batch at a time; blocks above it are saved individually.
+func repairStore(ctx context.Context, name string, fetcher *rpc.Fetcher, store *storage.PebbleStorage, issues []storage.Issue) error {
+	lastBatchEnd, _ := store.LatestBatch()
+
+	refetch := make(map[uint64]bool)
+	for _, issue := range issues {
+		switch issue.Kind {
+		case storage.IssueMeta:
+			if err := store.SaveMeta(issue.Start); err != nil {
+				return fmt.Errorf("save meta: %w", err)
+			}
+		case storage.IssueOverlap:
+			if err := store.DeleteBlockRange(issue.Start, issue.End); err != nil {
+				return fmt.Errorf("delete blocks %d-%d: %w", issue.Start, issue.End, err)
+			}
+		case storage.IssueParentHash:
+			// Either side of the link can be the stale one
+			for n := issue.Start - 1; n <= issue.End; n++ {
+				refetch[n] = true
+			}
+		default:
+			if issue.Start == 0 {
+				log.Printf("[%s] Cannot repair %s, remove the key manually", name, issue)
+				continue
+			}
+			for n := issue.Start; n <= issue.End; n++ {
+				refetch[n] = true
+			}
+		}
+	}
+
+	var blockNums []uint64
+	batches := make(map[uint64]bool)
+	for n := range refetch {
+		if n <= lastBatchEnd {
+			batches[storage.BatchStart(n)] = true
+		} else {
+			blockNums = append(blockNums, n)
+		}
+	}
+	sort.Slice(blockNums, func(i, j int) bool { return blockNums[i] < blockNums[j] })
+
+	for start := range batches {
+		log.Printf("[%s] Re-fetching batch %d-%d", name, start, storage.BatchEnd(start))
+		blocks := make([][]byte, 0, storage.BatchSize)
+		for n := start; n <= storage.BatchEnd(start); n++ {
+			data, err := fetchBlockJSON(ctx, fetcher, n)
+			if err != nil {
+				return err
+			}
+			blocks = append(blocks, data)
+		}
+		compressed, err := storage.CompressBlocks(blocks)
+		if err != nil {
+			return fmt.Errorf("compress batch %d: %w", start, err)
+		}
+		if err := store.SaveBatch(start, storage.BatchEnd(start), compressed); err != nil {
+			return fmt.Errorf("save batch %d: %w", start, err)
+		}
+		if err := store.DeleteBlockRange(start, storage.BatchEnd(start)); err != nil {
+			return fmt.Errorf("delete blocks %d-%d: %w", start, storage.BatchEnd(start), err)
+		}
+	}
+
+	for _, n := range blockNums {
+		log.Printf("[%s] Re-fetching block %d", name, n)
+		data, err := fetchBlockJSON(ctx, fetcher, n)
+		if err != nil {
+			return err
+		}
+		if err := store.SaveBlock(n, data); err != nil {
+			return fmt.Errorf("save block %d: %w", n, err)
+		}
+	}
+	return nil
+}
+
+func fetchBlockJSON(ctx context.Context, fetcher *rpc.Fetcher, blockNum uint64) ([]byte, error) {
+	block, err := fetcher.FetchBlock(ctx, blockNum)
+	if err != nil {
+		return nil, fmt.Errorf("fetch block %d: %w", blockNum, err)
+	}
+	data, err := json.Marshal(block)
+	if err != nil {
+		return nil, fmt.Errorf("marshal block %d: %w", blockNum, err)
+	}
+	return data, nil
+}